@@ -0,0 +1,120 @@
+package readwisereader
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DecodeWarning describes a value in an API response that did not have the
+// expected shape and was decoded on a best-effort basis.
+type DecodeWarning struct {
+	DocumentID string
+	// JSON name of the field
+	Field   string
+	Message string
+}
+
+func (w DecodeWarning) String() string {
+	return fmt.Sprintf("document %s: %s: %s", w.DocumentID, w.Field, w.Message)
+}
+
+// SetDecodeWarningHandler makes the client call fn for every decode anomaly
+// in documents it lists. fn may be called concurrently.
+func (c *Client) SetDecodeWarningHandler(fn func(DecodeWarning)) {
+	c.onDecodeWarning = fn
+}
+
+func (c *Client) reportAnomalies(lr *listResponse) {
+	if c.onDecodeWarning == nil {
+		return
+	}
+
+	for i := range lr.Results {
+		for _, w := range lr.Results[i].anomalies() {
+			c.onDecodeWarning(w)
+		}
+	}
+}
+
+func (dr *document) anomalies() []DecodeWarning {
+	var warnings []DecodeWarning
+	add := func(field, message string) {
+		if message != "" {
+			warnings = append(warnings, DecodeWarning{DocumentID: dr.ID, Field: field, Message: message})
+		}
+	}
+
+	add("published_date", dr.PublishedDate.anomaly)
+	add("tags", dr.Tags.anomaly)
+	return warnings
+}
+
+// tagsValue decodes tags, an object keyed by tag name despite the docs
+// saying otherwise. Lists of names are accepted too, and reported. The
+// object is kept as is in raw for fields Tag doesn't cover.
+type tagsValue struct {
+	tags    map[string]Tag
+	raw     map[string]any
+	anomaly string
+}
+
+func (tv *tagsValue) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*tv = tagsValue{}
+	switch value := v.(type) {
+	case map[string]any:
+		tv.raw = value
+		tv.tags = make(map[string]Tag, len(value))
+		for key, tag := range value {
+			fields, ok := tag.(map[string]any)
+			if !ok {
+				tv.anomaly = fmt.Sprintf("tag %q is a %T, not an object", key, tag)
+				tv.tags[key] = Tag{Name: key}
+				continue
+			}
+			tv.tags[key] = toTag(key, fields)
+		}
+	case []any:
+		tv.raw = make(map[string]any, len(value))
+		tv.tags = make(map[string]Tag, len(value))
+		for _, tag := range value {
+			name, ok := tag.(string)
+			if !ok {
+				tv.anomaly = fmt.Sprintf("tag list holds a %T", tag)
+				continue
+			}
+			tv.raw[name] = map[string]any{"name": name}
+			tv.tags[name] = Tag{Name: name}
+		}
+		if tv.anomaly == "" {
+			tv.anomaly = "tags is a list, not an object"
+		}
+	case nil:
+	default:
+		tv.anomaly = fmt.Sprintf("unexpected tags value type %T", v)
+	}
+
+	return nil
+}
+
+// toTag reads the fields of a tag object, naming the tag after its key when
+// it has no name.
+func toTag(key string, fields map[string]any) Tag {
+	tag := Tag{Name: key}
+	if name, ok := fields["name"].(string); ok && name != "" {
+		tag.Name = name
+	}
+	if typ, ok := fields["type"].(string); ok {
+		tag.Type = typ
+	}
+	if created, ok := fields["created"].(float64); ok {
+		tag.Created = time.UnixMilli(int64(created))
+	}
+
+	return tag
+}
@@ -0,0 +1,28 @@
+package readwisereader
+
+import (
+	"context"
+	"iter"
+)
+
+// API is the set of Reader operations implemented by Client, for code that
+// wants to depend on an interface rather than the concrete client.
+type API interface {
+	List(ctx context.Context, params ListParams) (*ListResponse, error)
+	ListPaginate(ctx context.Context, params ListParams) iter.Seq2[Page, error]
+	Save(ctx context.Context, params SaveParams) (*SaveResponse, error)
+	Update(ctx context.Context, ID string, params UpdateParams) (*UpdateResponse, error)
+	Delete(ctx context.Context, ID string) error
+}
+
+var _ API = (*Client)(nil)
+
+// ReaderAPI extends API with the conveniences Client builds on top of it.
+type ReaderAPI interface {
+	API
+	Get(ctx context.Context, ID string, opts ...GetOption) (*Document, error)
+	Documents(ctx context.Context, params ListParams) iter.Seq2[Document, error]
+	SaveBatch(ctx context.Context, batch []SaveParams, opts ...SaveBatchOption) ([]SaveResult, error)
+}
+
+var _ ReaderAPI = (*Client)(nil)
@@ -0,0 +1,28 @@
+package readwisereader
+
+import (
+	"context"
+	"net/http"
+)
+
+// ValidateToken checks the token with the API, failing with ErrUnauthorized
+// when it is rejected. A client rotating through several tokens succeeds as
+// long as one of them is accepted.
+func (c *Client) ValidateToken(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.addrV2+"/auth/", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	default:
+		return newAPIError(resp)
+	}
+}
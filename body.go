@@ -0,0 +1,120 @@
+package readwisereader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// maxResponseSize bounds how much of an API response is read into
+	// memory. Pages with html content are the largest by far.
+	maxResponseSize = 64 << 20
+	// maxDrain bounds how much of an unread body is discarded so that its
+	// connection can be reused, larger leftovers cost less as a new
+	// connection.
+	maxDrain = 256 << 10
+)
+
+// ErrResponseTooLarge is returned for API responses larger than the client is
+// willing to hold in memory.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// readBody reads all of r, failing once it exceeds limit bytes.
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrResponseTooLarge, limit)
+	}
+
+	return b, nil
+}
+
+// drainAndClose discards what is left of body and closes it, which lets the
+// transport put the connection back into its pool.
+func drainAndClose(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, maxDrain)
+	body.Close()
+}
+
+// decodeBody decodes the JSON body of resp into v, unless the request has
+// been canceled in the meantime.
+func decodeBody(resp *http.Response, v any) error {
+	if resp.Request != nil {
+		if err := resp.Request.Context().Err(); err != nil {
+			return err
+		}
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return decodeJSON(b, v)
+}
+
+// maxErrorBody bounds how much of an error response APIError keeps.
+const maxErrorBody = 64 << 10
+
+// newAPIError describes an unexpected response, picking the reason out of the
+// body in the shapes the API reports errors in: {"detail": "..."} and, for
+// invalid requests, messages keyed by field.
+func newAPIError(resp *http.Response) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode}
+
+	b, err := readBody(resp.Body, maxErrorBody)
+	if err != nil || len(b) == 0 {
+		return e
+	}
+	e.Body = b
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return e
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		if detail, ok := v["detail"].(string); ok {
+			e.Detail = detail
+			break
+		}
+
+		var problems []string
+		for _, field := range slices.Sorted(maps.Keys(v)) {
+			if msgs := errorMessages(v[field]); len(msgs) > 0 {
+				problems = append(problems, field+": "+strings.Join(msgs, " "))
+			}
+		}
+		e.Detail = strings.Join(problems, "; ")
+	case []any, string:
+		e.Detail = strings.Join(errorMessages(v), " ")
+	}
+
+	return e
+}
+
+func errorMessages(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var msgs []string
+		for _, m := range v {
+			msgs = append(msgs, errorMessages(m)...)
+		}
+		return msgs
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package readwisereader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores raw API responses. Keys are opaque, but share prefixes so that
+// related entries can be dropped together.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	DeletePrefix(prefix string)
+}
+
+// SetCache makes single page List calls consult cache, keeping responses for
+// ttl. Update and Delete invalidate the affected entries. Passing a nil cache
+// disables caching.
+func (c *Client) SetCache(cache Cache, ttl time.Duration) {
+	c.cache = cache
+	c.cacheTTL = ttl
+}
+
+const (
+	cachePrefixList     = "list?"
+	cachePrefixDocument = "document/"
+)
+
+func listCacheKey(params ListParams, query string) string {
+	if params.ID != "" {
+		return documentCachePrefix(params.ID) + query
+	}
+
+	return cachePrefixList + query
+}
+
+func documentCachePrefix(id string) string {
+	return cachePrefixDocument + id + "?"
+}
+
+// invalidate drops everything that may contain the document, its own entries
+// and every listing.
+func (c *Client) invalidate(id string) {
+	if c.cache == nil {
+		return
+	}
+
+	c.cache.DeletePrefix(documentCachePrefix(id))
+	c.cache.DeletePrefix(cachePrefixList)
+}
+
+// MemoryCache is an in-process Cache.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (e cacheEntry) expired() bool {
+	return time.Now().After(e.ExpiresAt)
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]cacheEntry{}}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if e.expired() {
+		delete(m.entries, key)
+		return nil, false
+	}
+
+	return e.Value, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = map[string]cacheEntry{}
+	}
+
+	m.entries[key] = cacheEntry{Key: key, Value: value, ExpiresAt: time.Now().Add(ttl)}
+}
+
+func (m *MemoryCache) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// DiskCache is a Cache keeping one file per entry in a directory, so that
+// entries survive across processes. Errors reading or writing the directory
+// are treated as cache misses.
+type DiskCache struct {
+	dir string
+}
+
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (d *DiskCache) read(path string) (cacheEntry, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var e cacheEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return cacheEntry{}, false
+	}
+
+	return e, true
+}
+
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	path := d.path(key)
+	e, ok := d.read(path)
+	if !ok || e.Key != key {
+		return nil, false
+	}
+
+	if e.expired() {
+		os.Remove(path)
+		return nil, false
+	}
+
+	return e.Value, true
+}
+
+func (d *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	b, err := json.Marshal(cacheEntry{Key: key, Value: value, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(d.dir, 0o700); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(d.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), d.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (d *DiskCache) DeletePrefix(prefix string) {
+	paths, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return
+	}
+
+	for _, path := range paths {
+		e, ok := d.read(path)
+		if !ok || strings.HasPrefix(e.Key, prefix) || e.expired() {
+			os.Remove(path)
+		}
+	}
+}
@@ -8,67 +8,91 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"log/slog"
 	"net/http"
-	"net/http/httputil"
-	"os"
 	"strconv"
 	"time"
 
 	"github.com/google/go-querystring/query"
 )
 
-const (
-	addr = "https://readwise.io/api/v3"
-)
+const defaultBaseURL = "https://readwise.io"
 
 type Client struct {
 	client http.Client
-	token  string
+	// The Reader API, and the classic Readwise API which owns highlights and
+	// their tags.
+	addr, addrV2 string
+	userAgent    string
+	timeout      time.Duration
+	retry        RetryPolicy
+	limiter      *rateLimiter
+	rateStatus   rateLimitState
+	logger       *slog.Logger
+
+	tokens    *tokenRing
+	scheduler *scheduler
+	cache     Cache
+	cacheTTL  time.Duration
+	stats     clientStats
+
+	onDecodeWarning func(DecodeWarning)
 }
 
-func NewClient(token string) *Client {
-	return &Client{
-		client: http.Client{
-			Transport: &authTransport{
-				Transport:           http.DefaultTransport.(*http.Transport),
-				authorizationHeader: fmt.Sprintf("Token %s", token),
-			},
-		},
-		token: token,
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
+		tokens:    newTokenRing([]string{token}),
+		scheduler: newScheduler(maxInFlight),
+	}
+	WithBaseURL(defaultBaseURL)(c)
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.timeout > 0 {
+		c.client.Timeout = c.timeout
 	}
+
+	return c
 }
 
 func (c *Client) List(ctx context.Context, params ListParams) (*ListResponse, error) {
-	lr, err := c.list(ctx, params)
+	return c.listPage(ctx, params, true)
+}
+
+func (c *Client) listPage(ctx context.Context, params ListParams, cached bool) (*ListResponse, error) {
+	start := time.Now()
+	lr, err := c.list(ctx, params, cached)
 	if err != nil {
 		return nil, err
 	}
 
 	r := lr.toListResponse()
+	r.Cursor = params.PageCursor
+	r.Duration = time.Since(start)
 	return &r, nil
 }
 
 func (c *Client) ListPaginate(ctx context.Context, params ListParams) iter.Seq2[Page, error] {
 	return func(yield func(Page, error) bool) {
 		cursor := params.PageCursor
+		var index, retries int
+		var last *Document
+
+		ctx := waitOutRateLimits(ctx, func(wait time.Duration) {
+			retries++
+			if params.OnRateLimited != nil {
+				params.OnRateLimited(wait)
+			}
+		})
 		for {
 			params.PageCursor = cursor
-			resp, err := c.List(ctx, params)
-			var rle *ErrorRateLimited
-			if errors.As(err, &rle) {
-				if ctx.Err() != nil {
-					yield(Page{}, ctx.Err())
-					return
-				}
-
-				// TODO: make this configurable or smth, a callback maybe?
-				select {
-				case <-time.After(rle.RetryAfter):
-					continue
-				case <-ctx.Done():
-					yield(Page{}, ctx.Err())
-					return
-				}
+			resp, err := c.listPage(ctx, params, false)
+			if errors.Is(err, ErrInvalidCursor) && params.RestartOnInvalidCursor && last != nil {
+				params.UpdatedAfter = last.UpdatedAt
+				cursor = ""
+				continue
 			}
 
 			if err != nil {
@@ -76,10 +100,19 @@ func (c *Client) ListPaginate(ctx context.Context, params ListParams) iter.Seq2[
 				return
 			}
 
+			if n := len(resp.Results); n > 0 {
+				last = &resp.Results[n-1]
+			}
+
+			resp.Index = index
+			resp.Retries = retries
 			if !yield(resp.Page, nil) {
 				return
 			}
 
+			index++
+			retries = 0
+
 			if resp.NextPageCursor == "" {
 				return
 			}
@@ -89,6 +122,25 @@ func (c *Client) ListPaginate(ctx context.Context, params ListParams) iter.Seq2[
 	}
 }
 
+// Documents yields every document matching params, following page cursors
+// like ListPaginate.
+func (c *Client) Documents(ctx context.Context, params ListParams) iter.Seq2[Document, error] {
+	return func(yield func(Document, error) bool) {
+		for page, err := range c.ListPaginate(ctx, params) {
+			if err != nil {
+				yield(Document{}, err)
+				return
+			}
+
+			for _, doc := range page.Results {
+				if !yield(doc, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
 type SaveParams struct {
 	URL             string     `json:"url"`
 	HTML            *string    `json:"html,omitempty"`
@@ -115,12 +167,70 @@ func (c *Client) Save(ctx context.Context, params SaveParams) (*SaveResponse, er
 	return &s, nil
 }
 
+type UpdateParams struct {
+	Title           *string    `json:"title,omitempty"`
+	Author          *string    `json:"author,omitempty"`
+	Summary         *string    `json:"summary,omitempty"`
+	PublishedDate   *time.Time `json:"published_date,omitempty"`
+	ImageURL        *string    `json:"image_url,omitempty"`
+	Location        Location   `json:"location,omitempty"`
+	Category        Category   `json:"category,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	Notes           *string    `json:"notes,omitempty"`
+	ReadingProgress *float64   `json:"reading_progress,omitempty"`
+
+	// Fields is the field mask. When empty, only non-zero fields are sent and
+	// fields can't be cleared. Otherwise exactly the listed fields are sent,
+	// with their zero value if unset. The Set methods maintain it.
+	Fields []UpdateField `json:"-"`
+
+	// UnmodifiedSince, when set, is the updated_at of the caller's copy of the
+	// document. Update then fails with a ConflictError instead of applying
+	// changes if the server copy was updated later. The API has no
+	// conditional updates, so this is checked with a separate request just
+	// before updating and can't rule out edits made in between.
+	UnmodifiedSince time.Time `json:"-"`
+}
+
+func (c *Client) Update(ctx context.Context, ID string, params UpdateParams) (*UpdateResponse, error) {
+	defer c.invalidate(ID)
+
+	if !params.UnmodifiedSince.IsZero() {
+		if err := c.checkUnmodified(ctx, ID, params.UnmodifiedSince); err != nil {
+			return nil, err
+		}
+	}
+
+	ur, err := c.update(ctx, ID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	u := ur.toUpdateResponse()
+	return &u, nil
+}
+
+func (c *Client) checkUnmodified(ctx context.Context, ID string, known time.Time) error {
+	// Bypass the cache, a stale copy would defeat the check.
+	doc, err := c.Get(ctx, ID, BypassCache())
+	if err != nil {
+		return err
+	}
+
+	if doc.UpdatedAt.After(known) {
+		return &ConflictError{Document: *doc, Known: known}
+	}
+
+	return nil
+}
+
 func (c *Client) Delete(ctx context.Context, ID string) error {
+	defer c.invalidate(ID)
 	return c.delete(ctx, ID)
 }
 
 func (c *Client) delete(ctx context.Context, ID string) error {
-	url := fmt.Sprintf("%s/delete/%s", addr, ID)
+	url := fmt.Sprintf("%s/delete/%s", c.addr, ID)
 	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
@@ -132,14 +242,46 @@ func (c *Client) delete(ctx context.Context, ID string) error {
 	}
 
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return newAPIError(resp)
 	}
 
 	return nil
 }
 
+func (c *Client) update(ctx context.Context, ID string, params UpdateParams) (*updateResponse, error) {
+	url := fmt.Sprintf("%s/update/%s/", c.addr, ID)
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var ur updateResponse
+	if err := decodeBody(resp, &ur); err != nil {
+		return nil, err
+	}
+
+	return &ur, nil
+}
+
 func (c *Client) save(ctx context.Context, params SaveParams) (*saveResponse, error) {
-	const url = addr + "/save"
+	url := c.addr + "/save"
 
 	b, err := json.Marshal(params)
 	if err != nil {
@@ -159,19 +301,22 @@ func (c *Client) save(ctx context.Context, params SaveParams) (*saveResponse, er
 	}
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError(resp)
 	}
 
 	var sr saveResponse
-	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+	if err := decodeBody(resp, &sr); err != nil {
 		return nil, err
 	}
 
+	// The API answers with 201 for new documents and 200 for known URLs.
+	sr.alreadyExists = resp.StatusCode == http.StatusOK
+
 	return &sr, nil
 }
 
-func (c *Client) list(ctx context.Context, params ListParams) (*listResponse, error) {
-	const url = addr + "/list"
+func (c *Client) list(ctx context.Context, params ListParams, cached bool) (*listResponse, error) {
+	url := c.addr + "/list"
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -184,77 +329,177 @@ func (c *Client) list(ctx context.Context, params ListParams) (*listResponse, er
 
 	req.URL.RawQuery = q.Encode()
 
+	cached = cached && c.cache != nil
+	key := listCacheKey(params, req.URL.RawQuery)
+	if cached {
+		if b, ok := c.cache.Get(key); ok {
+			var lr listResponse
+			if err := decodeJSON(b, &lr); err == nil {
+				c.reportAnomalies(&lr)
+				return &lr, nil
+			}
+		}
+	}
+
 	resp, err := c.do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	// The API answers a stale or otherwise unusable cursor with a bad request.
+	if resp.StatusCode == http.StatusBadRequest && params.PageCursor != "" {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, newAPIError(resp))
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError(resp)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
 	var lr listResponse
-	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+	if err := decodeJSON(b, &lr); err != nil {
 		return nil, err
 	}
 
+	c.reportAnomalies(&lr)
+
+	if cached {
+		c.cache.Set(key, b, c.cacheTTL)
+	}
+
 	return &lr, nil
 }
 
 func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	resp, err := c.client.Do(req.WithContext(ctx))
-	if err != nil {
-		return nil, fmt.Errorf("do: %w", err)
-	}
+	wo, waitingOut := ctx.Value(waitOutKey{}).(waitOut)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doAttempt(ctx, req)
+		wait, ok := c.retry.backoff(ctx, attempt, resp, err)
+
+		var rle *ErrorRateLimited
+		if waitingOut && errors.As(err, &rle) && ctx.Err() == nil {
+			// Rate limits waited out don't use up the attempts of the policy.
+			wait, ok = rle.RetryAfter, true
+			attempt--
+			if wo.onWait != nil {
+				wo.onWait(wait)
+			}
+		}
 
-	defer resp.Body.Close()
+		if !ok {
+			return resp, err
+		}
 
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("readall: %w", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
+}
 
-	resp.Body = io.NopCloser(bytes.NewReader(b))
+// doAttempt sends req once, or once per token when the API rejects or rate
+// limits the current one.
+func (c *Client) doAttempt(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := c.scheduler.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer c.scheduler.release()
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		retryAfter := resp.Header.Get("Retry-After")
-		seconds, err := strconv.Atoi(retryAfter)
+	for attempt := 1; ; attempt++ {
+		i, token := c.tokens.get()
+		resp, err := c.send(ctx, req, token)
 		if err != nil {
-			return nil, fmt.Errorf("invalid retry-after header: %v: %w", retryAfter, err)
+			return nil, err
 		}
 
-		errRateLimited := &ErrorRateLimited{
-			RetryAfter: time.Duration(seconds) * time.Second,
+		retry := attempt < c.tokens.len()
+
+		if resp.StatusCode == http.StatusUnauthorized && retry && c.tokens.rotate(i) {
+			continue
 		}
 
-		return nil, errRateLimited
-	}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter := resp.Header.Get("Retry-After")
+			seconds, err := strconv.Atoi(retryAfter)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retry-after header: %v: %w", retryAfter, err)
+			}
 
-	return resp, nil
-}
+			errRateLimited := &ErrorRateLimited{
+				RetryAfter: time.Duration(seconds) * time.Second,
+			}
+
+			// Only hold everyone back once no token has budget left.
+			wait := c.tokens.limit(i, errRateLimited.RetryAfter)
+			if wait == 0 && retry && c.tokens.rotate(i) {
+				continue
+			}
 
-type authTransport struct {
-	*http.Transport
-	authorizationHeader string
+			c.scheduler.pause(wait)
+
+			return nil, errRateLimited
+		}
+
+		return resp, nil
+	}
 }
 
-var _ http.RoundTripper = (*authTransport)(nil)
+// send makes a single attempt at req with token, returning the response with
+// its body read into memory.
+func (c *Client) send(ctx context.Context, req *http.Request, token string) (*http.Response, error) {
+	req = req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+
+	if err := c.limiter.wait(ctx, req); err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Token "+token)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	start := time.Now()
+	c.debug(ctx, "request", "method", req.Method, "url", req.URL.String(), "authorization", "Token [redacted]")
 
-func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", t.authorizationHeader)
-	// TODO: use slog
-	debug := os.Getenv("READWISE_DEBUG") != ""
-	resp, err := t.Transport.RoundTrip(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.stats.record(0, err)
+		c.debug(ctx, "request failed", "method", req.Method, "url", req.URL.String(), "error", err)
+		return nil, fmt.Errorf("do: %w", err)
+	}
 
-	if debug {
-		reqdump, _ := httputil.DumpRequestOut(req, true)
-		fmt.Println(string(reqdump))
+	defer drainAndClose(resp.Body)
+	c.stats.record(resp.StatusCode, nil)
+	c.rateStatus.observe(resp)
 
-		respdump, _ := httputil.DumpResponse(resp, true)
-		fmt.Println(string(respdump))
+	b, err := readBody(resp.Body, maxResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("readall: %w", err)
 	}
 
-	return resp, err
+	c.debug(ctx, "response",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"status", resp.StatusCode,
+		"bytes", len(b),
+		"duration", time.Since(start),
+	)
+
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	return resp, nil
 }
 
 type Location string
@@ -288,6 +533,23 @@ type ListParams struct {
 	Category        Category  `url:"category,omitempty"`
 	PageCursor      string    `url:"pageCursor,omitempty"`
 	WithHTMLContent bool      `url:"withHTMLContent,omitempty"`
+
+	// RestartOnInvalidCursor makes ListPaginate start over from the
+	// updated_at of the last document it yielded when the API rejects a page
+	// cursor, instead of failing. Documents may be yielded more than once
+	// after a restart.
+	RestartOnInvalidCursor bool `url:"-"`
+
+	// Locations and Categories list several values to match, the API only
+	// takes one of each per request. They are used by ListFanOut, which
+	// issues a request per combination; other methods ignore them.
+	Locations  []Location `url:"-"`
+	Categories []Category `url:"-"`
+
+	// OnRateLimited is called with the time ListPaginate waits before
+	// retrying a rate limited page. ListFanOut and ListSharded may call it
+	// from several goroutines at once.
+	OnRateLimited func(wait time.Duration) `url:"-"`
 }
 
 type listResponse struct {
@@ -314,12 +576,27 @@ func (lr *listResponse) toListResponse() ListResponse {
 type saveResponse struct {
 	ID  string `json:"id"`
 	URL string `json:"url"`
+
+	alreadyExists bool
 }
 
 func (sr *saveResponse) toSaveResponse() SaveResponse {
 	return SaveResponse{
-		ID:  sr.ID,
-		URL: sr.URL,
+		ID:            sr.ID,
+		URL:           sr.URL,
+		AlreadyExists: sr.alreadyExists,
+	}
+}
+
+type updateResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+func (ur *updateResponse) toUpdateResponse() UpdateResponse {
+	return UpdateResponse{
+		ID:  ur.ID,
+		URL: ur.URL,
 	}
 }
 
@@ -334,6 +611,14 @@ type Page struct {
 	Count int
 	// List of documents in the current page
 	Results []Document
+	// Position of the page within a ListPaginate run, starting at zero
+	Index int
+	// Cursor the page was requested with, empty for the first page
+	Cursor string
+	// Time the request for the page took
+	Duration time.Duration
+	// Number of times the request was retried after being rate limited
+	Retries int
 }
 
 type Document struct {
@@ -345,7 +630,8 @@ type Document struct {
 	Source          string
 	Category        Category
 	Location        Location
-	Tags            map[string]any
+	Tags            map[string]Tag
+	RawTags         map[string]any
 	SiteName        string
 	WordCount       int
 	CreatedAt       time.Time
@@ -354,6 +640,8 @@ type Document struct {
 	PublishedDate   time.Time
 	Summary         string
 	ImageURL        string
+	Content         string
+	HTMLContent     string
 	ParentID        string
 	ReadingProgress float64
 	FirstOpenedAt   time.Time
@@ -372,22 +660,24 @@ type document struct {
 	Category  Category `json:"category"`
 	Location  Location `json:"location"`
 	// NOTE: Doc says this is a []string, but actual response is an object with
-	// some metadata. Roll with any who cares.
-	Tags            map[string]any `json:"tags"`
-	SiteName        string         `json:"site_name"`
-	WordCount       int            `json:"word_count"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	Notes           string         `json:"notes"`
-	PublishedDate   dateValue      `json:"published_date"`
-	Summary         string         `json:"summary"`
-	ImageURL        string         `json:"image_url"`
-	ParentID        string         `json:"parent_id"`
-	ReadingProgress float64        `json:"reading_progress"`
-	FirstOpenedAt   time.Time      `json:"first_opened_at"`
-	LastOpenedAt    time.Time      `json:"last_opened_at"`
-	SavedAt         time.Time      `json:"saved_at"`
-	LastMovedAt     time.Time      `json:"last_moved_at"`
+	// some metadata.
+	Tags            tagsValue `json:"tags"`
+	SiteName        string    `json:"site_name"`
+	WordCount       int       `json:"word_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Notes           string    `json:"notes"`
+	PublishedDate   dateValue `json:"published_date"`
+	Summary         string    `json:"summary"`
+	ImageURL        string    `json:"image_url"`
+	Content         string    `json:"content"`
+	HTMLContent     string    `json:"html_content"`
+	ParentID        string    `json:"parent_id"`
+	ReadingProgress float64   `json:"reading_progress"`
+	FirstOpenedAt   time.Time `json:"first_opened_at"`
+	LastOpenedAt    time.Time `json:"last_opened_at"`
+	SavedAt         time.Time `json:"saved_at"`
+	LastMovedAt     time.Time `json:"last_moved_at"`
 }
 
 func (dr *document) toDocument() Document {
@@ -400,15 +690,18 @@ func (dr *document) toDocument() Document {
 		Source:          dr.Source,
 		Category:        dr.Category,
 		Location:        dr.Location,
-		Tags:            dr.Tags,
+		Tags:            dr.Tags.tags,
+		RawTags:         dr.Tags.raw,
 		SiteName:        dr.SiteName,
 		WordCount:       dr.WordCount,
 		CreatedAt:       dr.CreatedAt,
 		UpdatedAt:       time.Time(dr.UpdatedAt),
 		Notes:           dr.Notes,
-		PublishedDate:   time.Time(dr.PublishedDate),
+		PublishedDate:   dr.PublishedDate.Time,
 		Summary:         dr.Summary,
 		ImageURL:        dr.ImageURL,
+		Content:         dr.Content,
+		HTMLContent:     dr.HTMLContent,
 		ParentID:        dr.ParentID,
 		ReadingProgress: dr.ReadingProgress,
 		FirstOpenedAt:   time.Time(dr.FirstOpenedAt),
@@ -418,7 +711,12 @@ func (dr *document) toDocument() Document {
 	}
 }
 
-type dateValue time.Time
+// dateValue decodes the several shapes published_date comes in. Values it
+// can't make sense of decode to the zero time and are reported as anomalies.
+type dateValue struct {
+	time.Time
+	anomaly string
+}
 
 func (tv *dateValue) UnmarshalJSON(data []byte) error {
 	var v any
@@ -426,19 +724,21 @@ func (tv *dateValue) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	*tv = dateValue{}
 	switch value := v.(type) {
 	case float64:
-		*tv = dateValue(time.UnixMilli(int64(value)))
+		tv.Time = time.UnixMilli(int64(value))
 	case string:
-		t, err := time.Parse("2006-01-02", value)
-		if err != nil {
-			return err
+		if t, err := time.Parse(time.DateOnly, value); err == nil {
+			tv.Time = t
+		} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+			tv.Time = t
+		} else {
+			tv.anomaly = fmt.Sprintf("unparseable date %q", value)
 		}
-		*tv = dateValue(t)
 	case nil:
-		*tv = dateValue(time.Time{})
 	default:
-		return fmt.Errorf("invalid time value type: %T", v)
+		tv.anomaly = fmt.Sprintf("unexpected date value type %T", v)
 	}
 
 	return nil
@@ -447,6 +747,40 @@ func (tv *dateValue) UnmarshalJSON(data []byte) error {
 type SaveResponse struct {
 	ID  string
 	URL string
+	// AlreadyExists is set when the URL was saved before, in which case the
+	// existing document is returned.
+	AlreadyExists bool
+}
+
+type UpdateResponse struct {
+	ID  string
+	URL string
+}
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrInvalidCursor = errors.New("invalid page cursor")
+	ErrConflict      = errors.New("document modified remotely")
+	ErrUnauthorized  = errors.New("token rejected")
+	ErrValidation    = errors.New("request rejected as invalid")
+)
+
+// ConflictError is returned by Update when the document changed on the server
+// since UpdateParams.UnmodifiedSince. It matches ErrConflict.
+type ConflictError struct {
+	// The server copy of the document
+	Document Document
+	// The updated_at the caller expected
+	Known time.Time
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v: %s updated at %s, expected %s",
+		ErrConflict, e.Document.ID, e.Document.UpdatedAt.Format(time.RFC3339), e.Known.Format(time.RFC3339))
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
 }
 
 type ErrorRateLimited struct {
@@ -458,3 +792,36 @@ var _ error = (*ErrorRateLimited)(nil)
 func (e *ErrorRateLimited) Error() string {
 	return fmt.Sprintf("rate limited, retry after: %s", e.RetryAfter)
 }
+
+// APIError is an unexpected response from the API. It matches ErrNotFound,
+// ErrUnauthorized and ErrValidation with errors.Is by its status code.
+type APIError struct {
+	StatusCode int
+	// Detail is the reason the API gave, if any.
+	Detail string
+	// Body is the response body, as far as it was kept.
+	Body []byte
+}
+
+var _ error = (*APIError)(nil)
+
+func (e *APIError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("unexpected status code: %d: %s", e.StatusCode, e.Detail)
+	}
+
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	}
+
+	return false
+}
@@ -0,0 +1,220 @@
+package readwisereader_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/readwisereadertest"
+)
+
+// newDocuments builds n documents updated a minute apart, oldest first.
+func newDocuments(n int) []readwisereader.Document {
+	start := time.Now().UTC().Truncate(time.Second).Add(-time.Duration(n) * time.Minute)
+
+	docs := make([]readwisereader.Document, 0, n)
+	for i := range n {
+		updated := start.Add(time.Duration(i) * time.Minute)
+		docs = append(docs, readwisereadertest.NewDocument().WithTimes(updated, updated).Build())
+	}
+	return docs
+}
+
+func ids(docs []readwisereader.Document) []string {
+	var ids []string
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	return ids
+}
+
+func TestListPaginate(t *testing.T) {
+	docs := newDocuments(25)
+	srv := readwisereadertest.NewServer(docs...)
+	defer srv.Close()
+	srv.PageSize = 10
+
+	var got []readwisereader.Document
+	var pages []int
+	for page, err := range srv.Client().ListPaginate(context.Background(), readwisereader.ListParams{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page.Index)
+		got = append(got, page.Results...)
+	}
+
+	if want := []int{0, 1, 2}; !slices.Equal(pages, want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+	if !slices.Equal(ids(got), ids(docs)) {
+		t.Errorf("listed %v, want %v", ids(got), ids(docs))
+	}
+	if n := srv.Requests(); n != 3 {
+		t.Errorf("sent %d requests, want 3", n)
+	}
+}
+
+func TestWaitOutRateLimits(t *testing.T) {
+	docs := newDocuments(4)
+	srv := readwisereadertest.NewServer(docs...)
+	defer srv.Close()
+	srv.SetRateLimit(2, time.Second)
+	client := srv.Client()
+
+	ctx := context.Background()
+	for _, doc := range docs[:2] {
+		if _, err := client.Get(ctx, doc.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rle *readwisereader.ErrorRateLimited
+	_, err := client.Get(ctx, docs[2].ID)
+	if !errors.As(err, &rle) {
+		t.Fatalf("Get past the rate limit: err = %v, want ErrorRateLimited", err)
+	}
+	if rle.RetryAfter <= 0 || rle.RetryAfter > time.Second {
+		t.Errorf("RetryAfter = %v, want the rest of the window", rle.RetryAfter)
+	}
+
+	ctx = readwisereader.WaitOutRateLimits(ctx)
+	for _, doc := range docs[2:] {
+		got, err := client.Get(ctx, doc.ID)
+		if err != nil {
+			t.Fatalf("Get waiting out rate limits: %v", err)
+		}
+		if got.ID != doc.ID {
+			t.Errorf("Get(%s) = %s", doc.ID, got.ID)
+		}
+	}
+}
+
+func TestListPaginateRestartOnInvalidCursor(t *testing.T) {
+	for _, restart := range []bool{false, true} {
+		docs := newDocuments(30)
+		srv := readwisereadertest.NewServer(docs...)
+		defer srv.Close()
+		srv.PageSize = 10
+		client := srv.Client()
+
+		ctx := context.Background()
+		params := readwisereader.ListParams{RestartOnInvalidCursor: restart}
+
+		var got []readwisereader.Document
+		var err error
+		for page, perr := range client.ListPaginate(ctx, params) {
+			if perr != nil {
+				err = perr
+				break
+			}
+			got = append(got, page.Results...)
+
+			if page.Index == 0 {
+				// Shrink the listing below the cursor of the next page, all
+				// but the last document listed and the last five.
+				for _, doc := range slices.Concat(docs[:9], docs[10:25]) {
+					if err := client.Delete(ctx, doc.ID); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+
+		if !restart {
+			if !errors.Is(err, readwisereader.ErrInvalidCursor) {
+				t.Errorf("without restart: err = %v, want ErrInvalidCursor", err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := ids(slices.Concat(docs[:10], docs[25:])); !slices.Equal(ids(got), want) {
+			t.Errorf("listed %v, want %v", ids(got), want)
+		}
+	}
+}
+
+func TestUpdateParamsMarshalJSON(t *testing.T) {
+	title := "Title"
+	tests := []struct {
+		name   string
+		params *readwisereader.UpdateParams
+		want   string
+	}{
+		{
+			name:   "no mask",
+			params: &readwisereader.UpdateParams{Title: &title, Location: readwisereader.LocationLater},
+			want:   `{"title":"Title","location":"later"}`,
+		},
+		{
+			name:   "no mask zero values",
+			params: &readwisereader.UpdateParams{Tags: []string{}},
+			want:   `{}`,
+		},
+		{
+			name:   "mask",
+			params: new(readwisereader.UpdateParams).SetTitle("").SetTags(),
+			want:   `{"tags":[],"title":""}`,
+		},
+		{
+			name: "mask leaves fields set directly out",
+			params: func() *readwisereader.UpdateParams {
+				p := new(readwisereader.UpdateParams).SetReadingProgress(0)
+				p.Title = &title
+				return p
+			}(),
+			want: `{"reading_progress":0}`,
+		},
+		{
+			name:   "published date cleared",
+			params: new(readwisereader.UpdateParams).SetPublishedDate(time.Time{}),
+			want:   `{"published_date":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+		})
+	}
+
+	if _, err := json.Marshal(readwisereader.UpdateParams{Fields: []readwisereader.UpdateField{"bogus"}}); err == nil {
+		t.Error("unknown field in the mask: err = nil")
+	}
+}
+
+func TestUpdateFieldMask(t *testing.T) {
+	doc := readwisereadertest.NewDocument().WithTitle("Title").WithAuthor("Author").WithTags("a", "b").Build()
+	srv := readwisereadertest.NewServer(doc)
+	defer srv.Close()
+	client := srv.Client()
+
+	ctx := context.Background()
+	if _, err := client.Update(ctx, doc.ID, *new(readwisereader.UpdateParams).SetTitle("").SetTags()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.Get(ctx, doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "" || len(got.Tags) != 0 {
+		t.Errorf("title %q and tags %v not cleared", got.Title, got.Tags)
+	}
+	if got.Author != "Author" {
+		t.Errorf("author = %q, want it untouched", got.Author)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// keyringService is the service tokens are stored under in the OS keychain,
+// with the profile as the account.
+const keyringService = "readerctl"
+
+// Where the token in use came from.
+const (
+	tokenFromFlag    = "flag"
+	tokenFromKeyring = "keychain"
+	tokenFromEnv     = "env"
+	tokenFromConfig  = "config"
+)
+
+const tokenPrompt = "Readwise access token (from https://readwise.io/access_token): "
+
+// keyringUser is the keychain account holding the token of the profile.
+func (r *rootCmd) keyringUser() string {
+	return cmp.Or(r.profile, "default")
+}
+
+// resolveToken returns the token to use and where it came from, looking at
+// --token, then the keychain, READERCTL_TOKEN and the config file.
+func (r *rootCmd) resolveToken() (token, source string, err error) {
+	if r.tokenFlag && r.token != "" {
+		return r.token, tokenFromFlag, nil
+	}
+
+	token, keyringErr := keyring.Get(keyringService, r.keyringUser())
+	if keyringErr == nil && token != "" {
+		return token, tokenFromKeyring, nil
+	}
+
+	if r.token != "" {
+		if os.Getenv("READERCTL_TOKEN") == r.token {
+			return r.token, tokenFromEnv, nil
+		}
+		return r.token, tokenFromConfig, nil
+	}
+
+	err = errNoToken
+	if r.profile != "" {
+		err = fmt.Errorf("no token configured for profile %q, run readerctl --profile %s auth login", r.profile, r.profile)
+	}
+	if keyringErr != nil && !errors.Is(keyringErr, keyring.ErrNotFound) {
+		err = fmt.Errorf("%w (keychain: %v)", err, keyringErr)
+	}
+
+	return "", "", err
+}
+
+// hasFlag reports whether the command line args set the flag.
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--"+name || strings.HasPrefix(arg, "--"+name+"=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readSecret asks for a secret on a terminal without echoing it, returning
+// an empty string when stdin isn't one.
+func readSecret(stdin io.Reader, stderr io.Writer, prompt string) (string, error) {
+	f, ok := stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return "", nil
+	}
+
+	fmt.Fprint(stderr, prompt)
+	b, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(stderr)
+	if err != nil {
+		return "", fmt.Errorf("read token: %w", err)
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+type authCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newAuthCmd(root *rootCmd) *authCmd {
+	cmd := &authCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("auth").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "auth",
+		Usage:     "readerctl auth <SUBCOMMAND> ...",
+		ShortHelp: "manage and check the API token",
+		LongHelp: `The token is looked up from --token, then the OS keychain, then
+READERCTL_TOKEN and last the config file. Every profile has a keychain
+entry of its own.`,
+		Flags: cmd.flags,
+	}
+
+	newAuthLoginCmd(cmd)
+	newAuthLogoutCmd(cmd)
+	newAuthStatusCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type authLoginCmd struct {
+	*authCmd
+	noVerify bool
+	flags    *ff.FlagSet
+	command  *ff.Command
+}
+
+func newAuthLoginCmd(parent *authCmd) *authLoginCmd {
+	cmd := &authLoginCmd{authCmd: parent}
+	cmd.flags = ff.NewFlagSet("login").SetParent(parent.flags)
+	cmd.flags.BoolVar(&cmd.noVerify, 0, "no-verify", "store the token without checking it against the API")
+	cmd.command = &ff.Command{
+		Name:      "login",
+		Usage:     "readerctl auth login [FLAGS]",
+		ShortHelp: "store a token in the OS keychain",
+		LongHelp: `Stores the token given with --token in the keychain, or one asked for
+on a terminal, or read from the first line of stdin otherwise. The token is
+checked against the API first.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *authLoginCmd) exec(ctx context.Context, args []string) error {
+	token, err := c.loginToken()
+	if err != nil {
+		return err
+	}
+
+	if !c.noVerify {
+		client, err := c.newClient(token)
+		if err != nil {
+			return err
+		}
+
+		err = client.ValidateToken(ctx)
+		if errors.Is(err, readwisereader.ErrUnauthorized) {
+			return errors.New("token rejected by the API")
+		}
+		if err != nil {
+			return fmt.Errorf("verify token: %w", err)
+		}
+	}
+
+	if err := keyring.Set(keyringService, c.keyringUser(), token); err != nil {
+		return fmt.Errorf("store token in the keychain: %w", err)
+	}
+
+	fmt.Fprintf(c.stderr, "token stored in the keychain for profile %s\n", c.keyringUser())
+	if c.configHasToken() {
+		fmt.Fprintf(c.stderr, "%s still has a token in plain text, it can be removed now\n", c.config)
+	}
+
+	return nil
+}
+
+func (c *authLoginCmd) loginToken() (string, error) {
+	if c.tokenFlag && c.token != "" {
+		return c.token, nil
+	}
+
+	token, err := readSecret(c.stdin, c.stderr, tokenPrompt)
+	if err != nil || token != "" {
+		return token, err
+	}
+
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read token: %w", err)
+	}
+	if token = strings.TrimSpace(line); token == "" {
+		return "", &usageError{err: errors.New("expected a token on stdin or with --token")}
+	}
+
+	return token, nil
+}
+
+// configHasToken reports whether the config file sets a token for the
+// profile.
+func (c *authLoginCmd) configHasToken() bool {
+	b, err := os.ReadFile(c.config)
+	if err != nil {
+		return false
+	}
+
+	lines := strings.Split(string(b), "\n")
+	start, end := configSectionRange(lines, c.profile)
+	if start < 0 {
+		return false
+	}
+
+	for _, line := range lines[start:end] {
+		if name, _, ok := parseConfigLine(line); ok && name == "token" {
+			return true
+		}
+	}
+
+	return false
+}
+
+type authLogoutCmd struct {
+	*authCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newAuthLogoutCmd(parent *authCmd) *authLogoutCmd {
+	cmd := &authLogoutCmd{authCmd: parent}
+	cmd.flags = ff.NewFlagSet("logout").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "logout",
+		Usage:     "readerctl auth logout",
+		ShortHelp: "remove the token from the OS keychain",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *authLogoutCmd) exec(ctx context.Context, args []string) error {
+	err := keyring.Delete(keyringService, c.keyringUser())
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("no token in the keychain for profile %s", c.keyringUser())
+	}
+	if err != nil {
+		return fmt.Errorf("remove token from the keychain: %w", err)
+	}
+
+	fmt.Fprintf(c.stderr, "token removed from the keychain for profile %s\n", c.keyringUser())
+	return nil
+}
+
+type authStatusCmd struct {
+	*authCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newAuthStatusCmd(parent *authCmd) *authStatusCmd {
+	cmd := &authStatusCmd{authCmd: parent}
+	cmd.flags = ff.NewFlagSet("status").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "status",
+		Usage:     "readerctl auth status",
+		ShortHelp: "check the token and show where it came from",
+		LongHelp: "Validates the token against the API and prints where it came from, " +
+			"flag, keychain, env or config, and the rate limit standing the API " +
+			"reported. Exits with 1 when the token is rejected.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+type authStatus struct {
+	Profile   string           `json:"profile"`
+	Source    string           `json:"source"`
+	Valid     bool             `json:"valid"`
+	RateLimit *rateLimitStatus `json:"rate_limit,omitempty"`
+}
+
+type rateLimitStatus struct {
+	Limit     int        `json:"limit"`
+	Remaining int        `json:"remaining"`
+	Reset     *time.Time `json:"reset,omitempty"`
+}
+
+func (c *authStatusCmd) exec(ctx context.Context, args []string) error {
+	if c.output != outputTable && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	token, source, err := c.resolveToken()
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newClient(token)
+	if err != nil {
+		return err
+	}
+
+	status := authStatus{Profile: c.keyringUser(), Source: source, Valid: true}
+	err = client.ValidateToken(ctx)
+	switch {
+	case errors.Is(err, readwisereader.ErrUnauthorized):
+		status.Valid = false
+	case err != nil:
+		return fmt.Errorf("verify token: %w", err)
+	}
+
+	if rl := client.RateLimitStatus(); !rl.ObservedAt.IsZero() {
+		status.RateLimit = &rateLimitStatus{Limit: rl.Limit, Remaining: rl.Remaining}
+		if !rl.Reset.IsZero() {
+			status.RateLimit.Reset = &rl.Reset
+		}
+	}
+
+	if c.output == outputJSON {
+		if err := writeJSON(c.stdout, status); err != nil {
+			return err
+		}
+	} else {
+		c.writeStatus(status)
+	}
+
+	if !status.Valid {
+		return exitCode(1, nil)
+	}
+
+	return nil
+}
+
+func (c *authStatusCmd) writeStatus(status authStatus) {
+	valid := "yes"
+	if !status.Valid {
+		valid = "no, rejected by the API"
+	}
+
+	rateLimit := "not reported by the API"
+	if rl := status.RateLimit; rl != nil {
+		rateLimit = fmt.Sprintf("%d of %d requests left", rl.Remaining, rl.Limit)
+		if rl.Reset != nil {
+			rateLimit += fmt.Sprintf(", resets in %s", time.Until(*rl.Reset).Round(time.Second))
+		}
+	}
+
+	fmt.Fprintf(c.stdout, "profile:    %s\n", status.Profile)
+	fmt.Fprintf(c.stdout, "token from: %s\n", status.Source)
+	fmt.Fprintf(c.stdout, "valid:      %s\n", valid)
+	fmt.Fprintf(c.stdout, "rate limit: %s\n", rateLimit)
+}
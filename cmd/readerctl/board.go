@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// boardLocations are the columns of the board, in order.
+var boardLocations = []readwisereader.Location{
+	readwisereader.LocationNew,
+	readwisereader.LocationLater,
+	readwisereader.LocationShortList,
+	readwisereader.LocationArchive,
+}
+
+var (
+	boardColumnStyle  = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	boardFocusedStyle = boardColumnStyle.BorderForeground(lipgloss.Color("12"))
+	boardHeaderStyle  = lipgloss.NewStyle().Bold(true)
+	boardCursorStyle  = lipgloss.NewStyle().Reverse(true)
+	boardFaintStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+type boardCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newBoardCmd(root *rootCmd) *boardCmd {
+	cmd := &boardCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("board").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "board",
+		Usage:     "readerctl board",
+		ShortHelp: "triage documents on a board with a column per location",
+		LongHelp: `Documents are read from the local cache, run readerctl sync first. Moves
+are sent to Reader right away and written to the cache.
+
+Keys: h/l or ←/→ pick a column, j/k or ↑/↓ pick a document, H/L or </> move
+it to the previous or next column, 1-4 move it to that column, / filters by
+title, author or site, esc clears the filter, q quits.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *boardCmd) exec(ctx context.Context, args []string) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	store := c.store()
+	docs, err := store.Documents(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := newBoardModel(ctx, client, store, docs)
+	_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx), tea.WithInput(c.stdin), tea.WithOutput(c.stdout)).Run()
+	return err
+}
+
+type boardModel struct {
+	ctx    context.Context
+	client *readwisereader.Client
+	store  *sync.FileStore
+
+	columns [][]readwisereader.Document
+	focus   int
+	cursor  []int
+
+	filter    string
+	filtering bool
+
+	width, height int
+	status        string
+}
+
+// boardMoved reports the outcome of moving a document to another column.
+type boardMoved struct {
+	doc      readwisereader.Document
+	from, to int
+	err      error
+}
+
+func newBoardModel(ctx context.Context, client *readwisereader.Client, store *sync.FileStore, docs []readwisereader.Document) *boardModel {
+	m := &boardModel{
+		ctx:     ctx,
+		client:  client,
+		store:   store,
+		columns: make([][]readwisereader.Document, len(boardLocations)),
+		cursor:  make([]int, len(boardLocations)),
+	}
+
+	for _, doc := range docs {
+		if doc.ParentID != "" {
+			continue
+		}
+
+		if i := slices.Index(boardLocations, doc.Location); i >= 0 {
+			m.columns[i] = append(m.columns[i], doc)
+		}
+	}
+
+	return m
+}
+
+func (m *boardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *boardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case boardMoved:
+		if msg.err != nil {
+			// Put the document back where it was.
+			m.remove(msg.to, msg.doc.ID)
+			msg.doc.Location = boardLocations[msg.from]
+			m.columns[msg.from] = slices.Insert(m.columns[msg.from], 0, msg.doc)
+			m.status = fmt.Sprintf("moving %q failed: %v", msg.doc.Title, msg.err)
+			break
+		}
+		m.status = fmt.Sprintf("moved %q to %s", msg.doc.Title, boardLocations[msg.to])
+	case tea.KeyMsg:
+		if m.filtering {
+			return m, m.updateFilter(msg)
+		}
+		return m, m.updateKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m *boardModel) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering, m.filter = false, ""
+	case tea.KeyBackspace:
+		if m.filter != "" {
+			r := []rune(m.filter)
+			m.filter = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	case tea.KeyCtrlC:
+		return tea.Quit
+	}
+
+	m.clampCursors()
+	return nil
+}
+
+func (m *boardModel) updateKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "left", "h":
+		m.focus = max(m.focus-1, 0)
+	case "right", "l":
+		m.focus = min(m.focus+1, len(m.columns)-1)
+	case "up", "k":
+		m.cursor[m.focus] = max(m.cursor[m.focus]-1, 0)
+	case "down", "j":
+		m.cursor[m.focus] = min(m.cursor[m.focus]+1, max(len(m.visible(m.focus))-1, 0))
+	case "shift+left", "H", "<":
+		return m.move(m.focus - 1)
+	case "shift+right", "L", ">":
+		return m.move(m.focus + 1)
+	case "1", "2", "3", "4":
+		return m.move(int(msg.Runes[0] - '1'))
+	case "/":
+		m.filtering = true
+	case "esc":
+		m.filter = ""
+		m.clampCursors()
+	}
+
+	return nil
+}
+
+// move moves the selected document to column to, updating the board right
+// away and Reader in the background.
+func (m *boardModel) move(to int) tea.Cmd {
+	from := m.focus
+	if to < 0 || to >= len(m.columns) || to == from {
+		return nil
+	}
+
+	visible := m.visible(from)
+	if len(visible) == 0 {
+		return nil
+	}
+
+	doc := visible[m.cursor[from]]
+	m.remove(from, doc.ID)
+	doc.Location = boardLocations[to]
+	m.columns[to] = slices.Insert(m.columns[to], 0, doc)
+	m.clampCursors()
+	m.status = fmt.Sprintf("moving %q to %s…", doc.Title, doc.Location)
+
+	return func() tea.Msg {
+		var params readwisereader.UpdateParams
+		params.SetLocation(doc.Location)
+		if _, err := m.client.Update(m.ctx, doc.ID, params); err != nil {
+			return boardMoved{doc: doc, from: from, to: to, err: err}
+		}
+
+		err := m.store.Put(m.ctx, []readwisereader.Document{doc})
+		return boardMoved{doc: doc, from: from, to: to, err: err}
+	}
+}
+
+func (m *boardModel) remove(column int, id string) {
+	m.columns[column] = slices.DeleteFunc(m.columns[column], func(d readwisereader.Document) bool {
+		return d.ID == id
+	})
+}
+
+// visible returns the documents of a column matching the filter.
+func (m *boardModel) visible(column int) []readwisereader.Document {
+	if m.filter == "" {
+		return m.columns[column]
+	}
+
+	filter := strings.ToLower(m.filter)
+	var docs []readwisereader.Document
+	for _, doc := range m.columns[column] {
+		for _, s := range []string{doc.Title, documentAuthor(doc), documentSite(doc)} {
+			if strings.Contains(strings.ToLower(s), filter) {
+				docs = append(docs, doc)
+				break
+			}
+		}
+	}
+
+	return docs
+}
+
+func (m *boardModel) clampCursors() {
+	for i := range m.columns {
+		m.cursor[i] = min(m.cursor[i], max(len(m.visible(i))-1, 0))
+	}
+}
+
+func (m *boardModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	// Borders and padding take four columns, borders, header and footer
+	// four rows.
+	width := max(m.width/len(m.columns)-4, 10)
+	rows := max(m.height-6, 1)
+
+	columns := make([]string, len(m.columns))
+	for i := range m.columns {
+		visible := m.visible(i)
+
+		count := fmt.Sprint(len(m.columns[i]))
+		if m.filter != "" {
+			count = fmt.Sprintf("%d/%d", len(visible), len(m.columns[i]))
+		}
+
+		lines := []string{boardHeaderStyle.Render(truncateRunes(fmt.Sprintf("%s (%s)", strings.ToUpper(string(boardLocations[i])), count), width))}
+
+		// Scroll just enough to keep the cursor in view.
+		offset := max(m.cursor[i]-rows+1, 0)
+		for j := offset; j < len(visible) && j < offset+rows; j++ {
+			title := visible[j].Title
+			if title == "" {
+				title = documentLink(visible[j])
+			}
+			line := truncateRunes(title, width)
+			if i == m.focus && j == m.cursor[i] {
+				line = boardCursorStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+
+		style := boardColumnStyle
+		if i == m.focus {
+			style = boardFocusedStyle
+		}
+		columns[i] = style.Width(width + 2).Height(rows + 1).Render(strings.Join(lines, "\n"))
+	}
+
+	footer := boardFaintStyle.Render("h/l column · j/k document · H/L move · 1-4 move to · / filter · q quit")
+	switch {
+	case m.filtering:
+		footer = "/" + m.filter + "█"
+	case m.status != "":
+		footer = m.status
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...) + "\n" + footer
+}
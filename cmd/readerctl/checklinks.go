@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type checkLinksCmd struct {
+	*rootCmd
+	all     bool
+	tag     string
+	workers int
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newCheckLinksCmd(root *rootCmd) *checkLinksCmd {
+	cmd := &checkLinksCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("check-links").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.all, 0, "all", "report every checked link, not only dead ones")
+	cmd.flags.StringVar(&cmd.tag, 0, "tag", "", "tag documents with dead links with this tag")
+	cmd.flags.IntVar(&cmd.workers, 0, "workers", 8, "number of concurrent checks")
+	cmd.command = &ff.Command{
+		Name:      "check-links",
+		Usage:     "readerctl check-links [FLAGS]",
+		ShortHelp: "find documents whose source URL is gone",
+		LongHelp:  "Checks the documents in the local cache, run readerctl sync first.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *checkLinksCmd) exec(ctx context.Context, args []string) error {
+	if !isTabular(c.output) && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	var client *readwisereader.Client
+	if c.tag != "" {
+		var err error
+		if client, err = c.client(); err != nil {
+			return err
+		}
+	}
+
+	checker := readwisereader.LinkChecker{Workers: c.workers}
+
+	type row struct {
+		ID     string `json:"id"`
+		URL    string `json:"url"`
+		Status int    `json:"status,omitempty"`
+		Error  string `json:"error,omitempty"`
+		Dead   bool   `json:"dead"`
+	}
+
+	var rows []row
+	for result, err := range checker.Check(ctx, c.cachedDocuments(ctx)) {
+		if err != nil {
+			return err
+		}
+
+		if !result.Dead && !c.all {
+			continue
+		}
+
+		r := row{
+			ID:     result.Document.ID,
+			URL:    result.Document.SourceURL,
+			Status: result.StatusCode,
+			Dead:   result.Dead,
+		}
+		if result.Err != nil {
+			r.Error = result.Err.Error()
+		}
+		rows = append(rows, r)
+
+		if result.Dead && client != nil {
+			if err := tagDocument(ctx, client, result.Document, c.tag); err != nil {
+				return fmt.Errorf("tag %s: %w", result.Document.ID, err)
+			}
+		}
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, rows)
+	}
+
+	t := newTable("ID", "URL", "STATUS", "DEAD")
+	for _, r := range rows {
+		status := strconv.Itoa(r.Status)
+		if r.Error != "" {
+			status = r.Error
+		}
+		t.add(r.ID, r.URL, status, strconv.FormatBool(r.Dead))
+	}
+
+	return c.writeTable(t)
+}
+
+// tagDocument adds tag to the tags doc already has.
+func tagDocument(ctx context.Context, client *readwisereader.Client, doc readwisereader.Document, tag string) error {
+	if _, ok := doc.Tags[tag]; ok {
+		return nil
+	}
+
+	tags := append(slices.Collect(maps.Keys(doc.Tags)), tag)
+	_, err := client.Update(ctx, doc.ID, readwisereader.UpdateParams{Tags: tags})
+	return err
+}
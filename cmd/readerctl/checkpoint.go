@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// checkpointInterval bounds how often a checkpoint is written while
+// documents are processed, pages always write one when they are done.
+const checkpointInterval = 5 * time.Second
+
+// checkpoint records how far a long running command got, so that it can pick
+// up where it stopped. A nil checkpoint records nothing.
+type checkpoint struct {
+	// Cursor of the page being processed, empty for the first one
+	Cursor string `json:"cursor,omitempty"`
+	// IDs of the documents done so far
+	Processed map[string]bool `json:"processed"`
+	// Bytes of the output written, for runs appending to a file. Records
+	// written after the checkpoint are dropped when resuming.
+	Offset    int64     `json:"offset,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	path    string
+	writeAt time.Time
+	// offset reports the bytes written so far, when set.
+	offset func() int64
+}
+
+// loadCheckpoint reads the checkpoint at path, an empty one if the file
+// doesn't exist. An empty path disables checkpointing.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	cp := &checkpoint{path: path}
+	if err := readJSONFile(path, cp); err != nil {
+		return nil, err
+	}
+
+	if cp.Processed == nil {
+		cp.Processed = map[string]bool{}
+	}
+
+	return cp, nil
+}
+
+// resumed reports whether the checkpoint was left behind by an earlier run.
+func (cp *checkpoint) resumed() bool {
+	return cp != nil && !cp.UpdatedAt.IsZero()
+}
+
+func (cp *checkpoint) done(id string) bool {
+	return cp != nil && cp.Processed[id]
+}
+
+// page records that the page requested with cursor is being processed.
+func (cp *checkpoint) page(cursor string) error {
+	if cp == nil {
+		return nil
+	}
+
+	cp.Cursor = cursor
+	return cp.write()
+}
+
+// record marks id as done, writing the checkpoint if the last write is older
+// than checkpointInterval.
+func (cp *checkpoint) record(id string) error {
+	if cp == nil {
+		return nil
+	}
+
+	cp.Processed[id] = true
+	if time.Since(cp.writeAt) < checkpointInterval {
+		return nil
+	}
+
+	return cp.write()
+}
+
+// track makes the checkpoint record how much of w was written.
+func (cp *checkpoint) track(w *countingWriter) {
+	if cp != nil {
+		cp.offset = func() int64 { return w.n }
+	}
+}
+
+func (cp *checkpoint) write() error {
+	if cp.offset != nil {
+		cp.Offset = cp.offset()
+	}
+	cp.UpdatedAt = time.Now()
+	cp.writeAt = cp.UpdatedAt
+	return writeJSONFile(cp.path, cp)
+}
+
+// remove deletes the checkpoint once the run it tracks is complete.
+func (cp *checkpoint) remove() error {
+	if cp == nil {
+		return nil
+	}
+
+	err := os.Remove(cp.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
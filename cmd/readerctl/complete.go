@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// maxCompletions bounds the document IDs offered, most recently updated first.
+const maxCompletions = 50
+
+type completeCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newCompleteCmd(root *rootCmd) *completeCmd {
+	cmd := &completeCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("__complete").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "__complete",
+		Usage:     "readerctl __complete <ids|tags> [<PREFIX>] | args <WORD>...",
+		ShortHelp: "print completion candidates, used by shell completion",
+		LongHelp: "Prints candidates from the local mirror or document cache, one per line, " +
+			"with a tab separated description where there is one. " +
+			"It never talks to the API so that completion stays fast. " +
+			"args takes the words of the command line after readerctl, " +
+			"the last one being completed, and prints what may come there.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *completeCmd) exec(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "args" {
+		return c.completeArgs(ctx, args[1:])
+	}
+
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("expected what to complete and an optional prefix")
+	}
+
+	var prefix string
+	if len(args) == 2 {
+		prefix = args[1]
+	}
+
+	switch args[0] {
+	case "ids":
+		return c.completeIDs(ctx, prefix)
+	case "tags":
+		return c.completeTags(ctx, prefix)
+	default:
+		return fmt.Errorf("unknown completion %q", args[0])
+	}
+}
+
+// completeIDs offers documents whose ID starts with prefix or whose title
+// contains it, from the SQLite mirror when there is one.
+func (c *completeCmd) completeIDs(ctx context.Context, prefix string) error {
+	docs, err := c.matchDocuments(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		title := strings.Join(strings.Fields(doc.Title), " ")
+		fmt.Fprintf(c.stdout, "%s\t%s\n", doc.ID, truncate(title, 60))
+	}
+
+	return nil
+}
+
+func (c *completeCmd) matchDocuments(ctx context.Context, prefix string) ([]readwisereader.Document, error) {
+	if _, err := os.Stat(c.mirrorPath()); !errors.Is(err, fs.ErrNotExist) {
+		store, err := c.mirror(false)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+
+		return store.Match(ctx, prefix, maxCompletions)
+	}
+
+	lower := strings.ToLower(prefix)
+
+	var docs []readwisereader.Document
+	for doc, err := range c.cachedDocuments(ctx) {
+		if err != nil {
+			return nil, err
+		}
+
+		if doc.ParentID != "" || !strings.HasPrefix(doc.ID, prefix) && !strings.Contains(strings.ToLower(doc.Title), lower) {
+			continue
+		}
+
+		if docs = append(docs, doc); len(docs) == maxCompletions {
+			break
+		}
+	}
+
+	return docs, nil
+}
+
+func (c *completeCmd) completeTags(ctx context.Context, prefix string) error {
+	seen := map[string]bool{}
+	for doc, err := range c.cachedDocuments(ctx) {
+		if err != nil {
+			return err
+		}
+
+		for tag := range doc.Tags {
+			if strings.HasPrefix(tag, prefix) {
+				seen[tag] = true
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	for _, tag := range tags {
+		fmt.Fprintln(c.stdout, tag)
+	}
+
+	return nil
+}
+
+// completeArgs offers what may come at the last of words: the subcommands,
+// flags, flag values or arguments of the command the words before it select.
+// Nothing is printed when the shell should fall back to completing files.
+func (c *completeCmd) completeArgs(ctx context.Context, words []string) error {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	cmd, current := c.rootCmd.command, words[len(words)-1]
+
+	// The flag the word being completed is the value of, if any.
+	var pending ff.Flag
+	var positional int
+	for _, word := range words[:len(words)-1] {
+		switch {
+		case pending != nil:
+			pending = nil
+		case word == "--":
+		case strings.HasPrefix(word, "-") && word != "-":
+			name, _, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if f, ok := cmd.Flags.GetFlag(name); ok && !hasValue && f.GetPlaceholder() != "" {
+				pending = f
+			}
+		default:
+			if sub := subcommand(cmd, word); sub != nil && positional == 0 {
+				cmd = sub
+			} else {
+				positional++
+			}
+		}
+	}
+
+	if pending != nil {
+		name, _ := pending.GetLongName()
+		return c.completeValue(ctx, name, current)
+	}
+
+	if strings.HasPrefix(current, "-") {
+		return cmd.Flags.WalkFlags(func(f ff.Flag) error {
+			if name, ok := f.GetLongName(); ok && strings.HasPrefix("--"+name, current) {
+				fmt.Fprintf(c.stdout, "--%s\t%s\n", name, f.GetUsage())
+			}
+			return nil
+		})
+	}
+
+	if len(cmd.Subcommands) > 0 && positional == 0 {
+		for _, sub := range cmd.Subcommands {
+			if !strings.HasPrefix(sub.Name, "__") && strings.HasPrefix(sub.Name, current) {
+				fmt.Fprintf(c.stdout, "%s\t%s\n", sub.Name, sub.ShortHelp)
+			}
+		}
+		return nil
+	}
+
+	return c.completeValue(ctx, usageArg(cmd.Usage, positional), current)
+}
+
+// completeValue offers values of the kind named by a flag or a usage
+// placeholder.
+func (c *completeCmd) completeValue(ctx context.Context, kind, prefix string) error {
+	var values []string
+	switch strings.ToLower(kind) {
+	case "id":
+		return c.completeIDs(ctx, prefix)
+	case "tag", "tags":
+		return c.completeTags(ctx, prefix)
+	case "location", "to":
+		for _, location := range knownLocations {
+			values = append(values, string(location))
+		}
+	case "category":
+		for _, category := range knownCategories {
+			values = append(values, string(category))
+		}
+	case "output":
+		values = knownOutputs
+	case "profile":
+		values = configProfiles(c.config)
+	}
+
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			fmt.Fprintln(c.stdout, value)
+		}
+	}
+
+	return nil
+}
+
+func subcommand(cmd *ff.Command, name string) *ff.Command {
+	for _, sub := range cmd.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// usageArg returns the placeholder of the nth argument in usage, ID for the
+// first of "readerctl tag add <ID> <TAG>...", and TAG for every one after it.
+func usageArg(usage string, n int) string {
+	var args []string
+	var repeated bool
+
+	fields := strings.Fields(usage)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case strings.HasPrefix(field, "-"):
+			// Skip the value of a flag, like --to <LOCATION>.
+			i++
+		case field == "...":
+			repeated = true
+		case strings.Contains(field, "<"):
+			name, _, _ := strings.Cut(field[strings.Index(field, "<")+1:], ">")
+			args = append(args, name)
+			repeated = strings.Contains(field, "...")
+		}
+	}
+
+	switch {
+	case n < len(args):
+		return args[n]
+	case repeated && len(args) > 0:
+		return args[len(args)-1]
+	default:
+		return ""
+	}
+}
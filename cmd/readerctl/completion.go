@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+// The completion scripts leave the work to readerctl __complete args, falling
+// back to completing files when it has nothing to offer.
+const (
+	bashCompletion = `# bash completion for readerctl
+_readerctl() {
+	local IFS=$'\n'
+	COMPREPLY=($(readerctl __complete args "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1))
+}
+complete -o default -F _readerctl readerctl
+`
+
+	zshCompletion = `#compdef readerctl
+# zsh completion for readerctl
+_readerctl() {
+	local -a lines values descriptions
+	local line
+	lines=("${(@f)$(readerctl __complete args "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	lines=(${lines:#})
+	if (( ! $#lines )); then
+		_files
+		return
+	fi
+
+	for line in $lines; do
+		values+=("${line%%$'\t'*}")
+		if [[ $line == *$'\t'* ]]; then
+			descriptions+=("${line%%$'\t'*} -- ${line#*$'\t'}")
+		else
+			descriptions+=("$line")
+		fi
+	done
+	compadd -U -l -d descriptions -a values
+}
+
+if [[ $zsh_eval_context[-1] == loadautofunc ]]; then
+	_readerctl "$@"
+else
+	compdef _readerctl readerctl
+fi
+`
+
+	fishCompletion = `# fish completion for readerctl
+function __readerctl_complete
+	set -l words (commandline -opc)
+	set -e words[1]
+	set -l candidates (readerctl __complete args $words (commandline -ct) 2>/dev/null)
+	if test (count $candidates) -eq 0
+		__fish_complete_path (commandline -ct)
+		return
+	end
+	printf '%s\n' $candidates
+end
+
+complete -c readerctl -f -a '(__readerctl_complete)'
+`
+)
+
+type completionCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newCompletionCmd(root *rootCmd) *completionCmd {
+	cmd := &completionCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("completion").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "completion",
+		Usage:     "readerctl completion <bash|zsh|fish>",
+		ShortHelp: "print a shell completion script",
+		LongHelp: `Prints the completion script for the shell. Commands, flags and their
+values are completed, and document IDs by their ID or title from the local
+mirror, or the document cache when there is no mirror, as in
+readerctl open <TAB>.
+
+  bash: source <(readerctl completion bash)
+  zsh:  readerctl completion zsh > "${fpath[1]}/_readerctl"
+  fish: readerctl completion fish > ~/.config/fish/completions/readerctl.fish`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *completionCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return &usageError{err: errors.New("expected a shell: bash, zsh or fish")}
+	}
+
+	var script string
+	switch args[0] {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return &usageError{err: fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", args[0])}
+	}
+
+	_, err := fmt.Fprint(c.stdout, script)
+	return err
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type configCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newConfigCmd(root *rootCmd) *configCmd {
+	cmd := &configCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("config").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "config",
+		Usage:     "readerctl config <SUBCOMMAND> ...",
+		ShortHelp: "manage the config file",
+		Flags:     cmd.flags,
+	}
+
+	newConfigInitCmd(cmd)
+	newConfigGetCmd(cmd)
+	newConfigSetCmd(cmd)
+	newConfigPathCmd(cmd)
+	newConfigValidateCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type configValidateCmd struct {
+	*configCmd
+	offline bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newConfigValidateCmd(parent *configCmd) *configValidateCmd {
+	cmd := &configValidateCmd{configCmd: parent}
+	cmd.flags = ff.NewFlagSet("validate").SetParent(parent.flags)
+	cmd.flags.BoolVar(&cmd.offline, 0, "offline", "don't verify the token against the API")
+	cmd.command = &ff.Command{
+		Name:      "validate",
+		Usage:     "readerctl config validate [FLAGS]",
+		ShortHelp: "check the config file for mistakes",
+		LongHelp: "Reports keys that aren't flags of any command and values the flags " +
+			"reject, with the line they are on, then checks that the token works.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+// configProblem is a mistake on a line of the config file.
+type configProblem struct {
+	line    int
+	message string
+}
+
+func (c *configValidateCmd) exec(ctx context.Context, args []string) error {
+	f, err := os.Open(c.config)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	problems, err := validateConfig(f)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range problems {
+		fmt.Fprintf(c.stderr, "%s:%d: %s\n", c.config, p.line, p.message)
+	}
+
+	if len(problems) > 0 {
+		return exitCode(1, fmt.Errorf("%d problems in %s", len(problems), c.config))
+	}
+
+	if !c.offline {
+		if err := c.checkToken(ctx); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "%s is valid\n", c.config)
+	return nil
+}
+
+func (c *configValidateCmd) checkToken(ctx context.Context) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	err = client.ValidateToken(ctx)
+	if errors.Is(err, readwisereader.ErrUnauthorized) {
+		return errors.New("token rejected by the API")
+	}
+	if err != nil {
+		return fmt.Errorf("verify token: %w", err)
+	}
+
+	return nil
+}
+
+// validateConfig checks a config file in ff's plain format against the flags
+// of every command.
+func validateConfig(r io.Reader) ([]configProblem, error) {
+	flags, err := configFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []configProblem
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		if _, ok := configSection(s.Text()); ok {
+			continue
+		}
+
+		name, value, ok := parseConfigLine(s.Text())
+		if !ok {
+			continue
+		}
+
+		if problem := checkConfigValue(flags, name, value); problem != "" {
+			problems = append(problems, configProblem{n, problem})
+		}
+	}
+
+	return problems, s.Err()
+}
+
+// configFlags returns the flags of every command by name, from a fresh
+// command tree so nothing leaks into this run.
+func configFlags() (map[string][]ff.Flag, error) {
+	root := newRootCmd(nil, io.Discard, io.Discard)
+	registerCommands(root)
+
+	flags := map[string][]ff.Flag{}
+	var walk func(cmd *ff.Command) error
+	walk = func(cmd *ff.Command) error {
+		if cmd.Flags != nil {
+			err := cmd.Flags.WalkFlags(func(f ff.Flag) error {
+				name, ok := f.GetLongName()
+				if ok && !slices.Contains(flags[name], f) {
+					flags[name] = append(flags[name], f)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, sub := range cmd.Subcommands {
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+	if err := walk(root.command); err != nil {
+		return nil, err
+	}
+
+	return flags, nil
+}
+
+// parseConfigLine splits a line of the config file into its key and value,
+// reporting false for blank lines and comments. It mirrors ff.PlainParser.
+func parseConfigLine(line string) (name, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || line[0] == '#' {
+		return "", "", false
+	}
+
+	name, value, ok = strings.Cut(line, " ")
+	if !ok {
+		value = "true"
+	}
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+
+	return name, value, true
+}
+
+// checkConfigValue returns what is wrong with setting name to value in the
+// config file, or an empty string if nothing is.
+func checkConfigValue(flags map[string][]ff.Flag, name, value string) string {
+	candidates, ok := flags[name]
+	switch {
+	case !ok:
+		return fmt.Sprintf("unknown key %q", name)
+	case name == "config":
+		return "config can't be set from the config file"
+	}
+
+	switch name {
+	case "output":
+		if !slices.Contains(knownOutputs, value) {
+			return fmt.Sprintf("output: %q is not one of %s", value, strings.Join(knownOutputs, ", "))
+		}
+	case "location":
+		if _, err := parseLocations(value); err != nil {
+			return fmt.Sprintf("location: %v", err)
+		}
+	case "category":
+		if _, err := parseCategories(value); err != nil {
+			return fmt.Sprintf("category: %v", err)
+		}
+	}
+
+	// Keys apply to every command with a flag of that name, so the value has
+	// to suit at least one of them.
+	var errs []error
+	for _, f := range candidates {
+		if err := f.SetValue(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(candidates) {
+		return fmt.Sprintf("%s: %v", name, errs[0])
+	}
+
+	return ""
+}
+
+type configPathCmd struct {
+	*configCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newConfigPathCmd(parent *configCmd) *configPathCmd {
+	cmd := &configPathCmd{configCmd: parent}
+	cmd.flags = ff.NewFlagSet("path").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "path",
+		Usage:     "readerctl config path",
+		ShortHelp: "print the path of the config file",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *configPathCmd) exec(ctx context.Context, args []string) error {
+	fmt.Fprintln(c.stdout, c.config)
+	return nil
+}
+
+type configInitCmd struct {
+	*configCmd
+	force   bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newConfigInitCmd(parent *configCmd) *configInitCmd {
+	cmd := &configInitCmd{configCmd: parent}
+	cmd.flags = ff.NewFlagSet("init").SetParent(parent.flags)
+	cmd.flags.BoolVar(&cmd.force, 0, "force", "overwrite an existing config file")
+	cmd.command = &ff.Command{
+		Name:      "init",
+		Usage:     "readerctl config init [FLAGS]",
+		ShortHelp: "create the config file",
+		LongHelp: `Creates the config file, readable only by you, with the token given by
+--token or READERCTL_TOKEN, or asked for when there is none, under the
+header of --profile if given. Set defaults afterwards with readerctl config
+set, like readerctl config set output json.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+// configTemplate is written by config init, followed by the token.
+const configTemplate = `# readerctl config: a flag name and its value per line, applying to every
+# command with that flag. Change it with readerctl config set.
+#
+# output json
+# location later
+#
+# Lines after a [NAME] header only apply with --profile NAME, or with
+# profile NAME set above the first header.
+`
+
+func (c *configInitCmd) exec(ctx context.Context, args []string) error {
+	if _, err := os.Stat(c.config); err == nil && !c.force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", c.config)
+	}
+
+	token := c.token
+	if token == "" {
+		var err error
+		if token, err = readSecret(c.stdin, c.stderr, tokenPrompt); err != nil {
+			return err
+		}
+	}
+
+	content := configTemplate
+	if c.profile != "" {
+		content += "\n[" + c.profile + "]\n"
+	}
+	if token != "" {
+		content += "\ntoken " + token + "\n"
+	}
+
+	if err := writeConfigFile(c.config, content); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "wrote %s\n", c.config)
+	return nil
+}
+
+type configGetCmd struct {
+	*configCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newConfigGetCmd(parent *configCmd) *configGetCmd {
+	cmd := &configGetCmd{configCmd: parent}
+	cmd.flags = ff.NewFlagSet("get").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "get",
+		Usage:     "readerctl config get [<KEY>]",
+		ShortHelp: "print values set in the config file",
+		LongHelp: "Prints the value of the key, a line for each time it is set, or every " +
+			"key and value without one, those of the --profile included. " +
+			"Exits with 1 when the key isn't set.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *configGetCmd) exec(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return &usageError{err: errors.New("expected at most one key")}
+	}
+
+	b, err := os.ReadFile(c.config)
+	if err != nil {
+		return err
+	}
+
+	// Values of the profile come after, and override, those for every
+	// profile.
+	lines := strings.Split(string(b), "\n")
+	var values [][2]string
+	for _, profile := range slices.Compact([]string{"", c.profile}) {
+		start, end := configSectionRange(lines, profile)
+		if start < 0 {
+			continue
+		}
+
+		var set [][2]string
+		for _, line := range lines[start:end] {
+			if name, value, ok := parseConfigLine(line); ok && (len(args) == 0 || name == args[0]) {
+				set = append(set, [2]string{name, value})
+			}
+		}
+		if len(args) == 1 && len(set) > 0 {
+			values = nil
+		}
+		values = append(values, set...)
+	}
+
+	if len(args) == 1 && len(values) == 0 {
+		return exitCode(1, fmt.Errorf("%s is not set in %s", args[0], c.config))
+	}
+
+	for _, v := range values {
+		if len(args) == 0 {
+			fmt.Fprintf(c.stdout, "%s %s\n", v[0], v[1])
+		} else {
+			fmt.Fprintln(c.stdout, v[1])
+		}
+	}
+
+	return nil
+}
+
+type configSetCmd struct {
+	*configCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newConfigSetCmd(parent *configCmd) *configSetCmd {
+	cmd := &configSetCmd{configCmd: parent}
+	cmd.flags = ff.NewFlagSet("set").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "set",
+		Usage:     "readerctl config set <KEY> <VALUE>",
+		ShortHelp: "set a value in the config file",
+		LongHelp: "Checks the value against the flags named by the key, then replaces " +
+			"the lines setting the key, keeping the rest of the file as it is, or " +
+			"adds one. With --profile the value is set for that profile only. " +
+			"The file is created if there is none.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *configSetCmd) exec(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return &usageError{err: errors.New("expected a key and a value")}
+	}
+
+	name, value := args[0], strings.Join(args[1:], " ")
+	if strings.TrimSpace(value) == "" || strings.Contains(value, " #") || strings.ContainsAny(value, "\r\n") {
+		return &usageError{err: fmt.Errorf("%s: value can't be empty, contain line breaks or \" #\"", name)}
+	}
+
+	flags, err := configFlags()
+	if err != nil {
+		return err
+	}
+	if problem := checkConfigValue(flags, name, value); problem != "" {
+		return &usageError{err: errors.New(problem)}
+	}
+
+	b, err := os.ReadFile(c.config)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	start, end := configSectionRange(lines, c.profile)
+	if start < 0 {
+		lines = append(lines, "", "["+c.profile+"]")
+		start, end = len(lines), len(lines)
+	}
+
+	// Replace the first line setting the key and drop the others, or add one
+	// after the last line of the section.
+	updated := slices.Clone(lines[:start])
+	var replaced bool
+	for _, line := range lines[start:end] {
+		if key, _, ok := parseConfigLine(line); ok && key == name {
+			if !replaced {
+				updated = append(updated, name+" "+value)
+				replaced = true
+			}
+			continue
+		}
+		updated = append(updated, line)
+	}
+	if !replaced {
+		i := len(updated)
+		for i > start && strings.TrimSpace(updated[i-1]) == "" {
+			i--
+		}
+		updated = slices.Insert(updated, i, name+" "+value)
+	}
+	updated = append(updated, lines[end:]...)
+
+	content := strings.TrimPrefix(strings.Join(updated, "\n"), "\n") + "\n"
+	return writeConfigFile(c.config, content)
+}
+
+// writeConfigFile replaces the config file at path, creating it and its
+// directory readable only by the user, as it holds the token.
+func writeConfigFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
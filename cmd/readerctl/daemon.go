@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type daemonCmd struct {
+	*rootCmd
+	interval       time.Duration
+	reconcileEvery time.Duration
+	hook           string
+	metricsAddr    string
+	trackContent   bool
+	metrics        daemonMetrics
+	flags          *ff.FlagSet
+	command        *ff.Command
+}
+
+func newDaemonCmd(root *rootCmd) *daemonCmd {
+	cmd := &daemonCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("daemon").SetParent(root.flags)
+	cmd.flags.DurationVar(&cmd.interval, 0, "interval", 15*time.Minute, "time between syncs")
+	cmd.flags.DurationVar(&cmd.reconcileEvery, 0, "reconcile-every", 24*time.Hour, "drop cached documents deleted in Reader this often, 0 to never")
+	cmd.flags.StringVar(&cmd.hook, 0, "hook", "", "shell command run after each sync that changed something, with the events as JSON lines on stdin")
+	cmd.flags.BoolVar(&cmd.trackContent, 0, "track-content", "fetch html content and publish content_changed events when it changes")
+	cmd.flags.StringVar(&cmd.metricsAddr, 0, "metrics-addr", "", "serve Prometheus metrics on this address at /metrics, e.g. :9464")
+	cmd.command = &ff.Command{
+		Name:      "daemon",
+		Usage:     "readerctl daemon [FLAGS]",
+		ShortHelp: "keep the local cache fresh by syncing periodically",
+		LongHelp: "Syncs right away and then every --interval until interrupted. " +
+			"Failed syncs are reported on stderr and retried at the next interval.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	newDaemonInstallCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *daemonCmd) exec(ctx context.Context, args []string) error {
+	if c.interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var events []sync.Event
+	opts := []sync.Option{sync.WithReconcileInterval(c.reconcileEvery)}
+	if c.trackContent {
+		opts = append(opts, sync.WithContentTracking())
+	}
+
+	syncer := sync.New(client, c.store(), opts...)
+	syncer.Subscribe(func(ctx context.Context, event sync.Event) {
+		if _, ok := event.(sync.SyncCompleted); !ok {
+			events = append(events, event)
+		}
+	})
+
+	script, err := c.loadScript(ctx, client)
+	if err != nil {
+		return err
+	}
+	script.subscribe(syncer, c.reportScriptError)
+
+	if c.metricsAddr != "" {
+		if err := c.serveMetrics(ctx, c.metricsAddr, client); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		// Subscribers are called synchronously, so events holds everything
+		// the run published once Sync returns.
+		events = events[:0]
+		err := c.tick(ctx, client, syncer)
+		if err == nil && len(events) > 0 && c.hook != "" {
+			err = c.runHook(ctx, events)
+		}
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", time.Now().Format(time.DateTime), err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (c *daemonCmd) tick(ctx context.Context, client *readwisereader.Client, syncer *sync.Syncer) error {
+	// Send queued operations first so the sync picks up their results.
+	if _, err := sync.NewQueue(client, c.store()).Flush(ctx); err != nil {
+		fmt.Fprintf(c.stderr, "%s: flush queue: %v\n", time.Now().Format(time.DateTime), err)
+	}
+
+	result, err := syncer.Sync(ctx)
+	c.metrics.recordSync(result, err)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	if err := c.resurfaceSnoozes(ctx, client); err != nil {
+		return fmt.Errorf("resurface snoozes: %w", err)
+	}
+
+	return nil
+}
+
+func (c *daemonCmd) runHook(ctx context.Context, events []sync.Event) error {
+	var stdin bytes.Buffer
+	enc := json.NewEncoder(&stdin)
+	for _, event := range events {
+		if err := enc.Encode(eventJSON(event)); err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.hook)
+	cmd.Stdin = &stdin
+	cmd.Stdout = c.stderr
+	cmd.Stderr = c.stderr
+	cmd.Env = append(os.Environ(), "READERCTL_EVENTS="+strconv.Itoa(len(events)))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook: %w", err)
+	}
+
+	return nil
+}
+
+// eventJSON shapes a sync event for hooks.
+func eventJSON(event sync.Event) map[string]any {
+	switch e := event.(type) {
+	case sync.DocumentAdded:
+		return map[string]any{"type": "document_added", "document": e.Document}
+	case sync.DocumentUpdated:
+		return map[string]any{"type": "document_updated", "document": e.Document}
+	case sync.DocumentArchived:
+		return map[string]any{"type": "document_archived", "document": e.Document, "from": e.From}
+	case sync.ContentChanged:
+		return map[string]any{"type": "content_changed", "document": e.Document, "previous_hash": e.PreviousHash, "hash": e.Hash}
+	case sync.DocumentDeleted:
+		return map[string]any{"type": "document_deleted", "document": e.Document}
+	case sync.TagAdded:
+		return map[string]any{"type": "tag_added", "document": e.Document, "tag": e.Tag}
+	case sync.SyncCompleted:
+		return map[string]any{"type": "sync_completed", "result": e.Result}
+	default:
+		return map[string]any{"type": fmt.Sprintf("%T", event)}
+	}
+}
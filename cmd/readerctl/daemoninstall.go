@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+const launchdLabel = "me.selman.readerctl"
+
+var systemdUnit = template.Must(template.New("systemd").Parse(`[Unit]
+Description=Readwise Reader sync
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart={{ .Command }}
+Restart=on-failure
+RestartSec=1min
+
+[Install]
+WantedBy=default.target
+`))
+
+var launchdPlist = template.Must(template.New("launchd").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{ xml .Label }}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Args }}
+		<string>{{ xml . }}</string>
+{{- end }}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>{{ xml .Log }}</string>
+</dict>
+</plist>
+`))
+
+type daemonInstallCmd struct {
+	*daemonCmd
+	kind    string
+	write   bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newDaemonInstallCmd(parent *daemonCmd) *daemonInstallCmd {
+	defaultKind := "systemd"
+	if runtime.GOOS == "darwin" {
+		defaultKind = "launchd"
+	}
+
+	cmd := &daemonInstallCmd{daemonCmd: parent}
+	cmd.flags = ff.NewFlagSet("install").SetParent(parent.flags)
+	cmd.flags.StringEnumVar(&cmd.kind, 0, "init", "service manager to write a unit for", defaultKind, "systemd", "launchd")
+	cmd.flags.BoolVar(&cmd.write, 0, "write", "write the unit to the user service directory instead of stdout")
+	cmd.command = &ff.Command{
+		Name:      "install",
+		Usage:     "readerctl daemon install [FLAGS]",
+		ShortHelp: "emit a systemd or launchd unit running the daemon",
+		LongHelp: "The unit runs the daemon with the current --config, --profile, --cache-dir and daemon flags. " +
+			"The token is not written to the unit, keep it in the config file.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *daemonInstallCmd) exec(ctx context.Context, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	daemonArgs := []string{exe, "--config", c.config}
+	if c.profile != "" {
+		daemonArgs = append(daemonArgs, "--profile", c.profile)
+	}
+	daemonArgs = append(daemonArgs,
+		"--cache-dir", c.cacheDir,
+		"daemon",
+		"--interval", c.interval.String(),
+		"--reconcile-every", c.reconcileEvery.String(),
+	)
+	if c.hook != "" {
+		daemonArgs = append(daemonArgs, "--hook", c.hook)
+	}
+
+	// Each profile gets a service of its own.
+	label, name := launchdLabel, "readerctl"
+	if c.profile != "" {
+		label, name = label+"."+c.profile, name+"-"+c.profile
+	}
+
+	var unit strings.Builder
+	var path string
+	switch c.kind {
+	case "launchd":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(home, "Library", "LaunchAgents", label+".plist")
+		err = launchdPlist.Execute(&unit, map[string]any{
+			"Label": label,
+			"Args":  daemonArgs,
+			"Log":   filepath.Join(c.cacheDir, "daemon.log"),
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(configDir, "systemd", "user", name+".service")
+		quoted := make([]string, len(daemonArgs))
+		for i, arg := range daemonArgs {
+			quoted[i] = systemdQuote(arg)
+		}
+		if err := systemdUnit.Execute(&unit, map[string]any{"Command": strings.Join(quoted, " ")}); err != nil {
+			return err
+		}
+	}
+
+	if !c.write {
+		_, err := fmt.Fprint(c.stdout, unit.String())
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(unit.String()), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "wrote %s\n", path)
+	if c.kind == "launchd" {
+		fmt.Fprintf(c.stderr, "load it with: launchctl load %s\n", path)
+	} else {
+		fmt.Fprintf(c.stderr, "enable it with: systemctl --user daemon-reload && systemctl --user enable --now %s\n", name)
+	}
+
+	return nil
+}
+
+func xmlEscape(s string) (string, error) {
+	var b strings.Builder
+	err := xml.EscapeText(&b, []byte(s))
+	return b.String(), err
+}
+
+// systemdQuote quotes an ExecStart argument when it needs it.
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$%;") {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`, `%`, `%%`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+type deleteCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newDeleteCmd(root *rootCmd) *deleteCmd {
+	cmd := &deleteCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("delete").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "delete",
+		Usage:     "readerctl delete [FLAGS] [<ID>...]",
+		ShortHelp: "delete documents from Reader",
+		LongHelp: `Deleted documents are dropped from the local cache and mirror too. Without
+IDs, or with -, IDs are read from stdin, one per line.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *deleteCmd) exec(ctx context.Context, args []string) error {
+	if c.output != outputTable && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	ids, err := documentIDs(args, c.stdin)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var deleted []string
+	for _, id := range ids {
+		if err := client.Delete(ctx, id); err != nil {
+			// Keep the local copies in step with what did get deleted.
+			return errors.Join(fmt.Errorf("delete %s: %w", id, err), c.forget(ctx, deleted))
+		}
+
+		deleted = append(deleted, id)
+		if c.output == outputTable {
+			fmt.Fprintln(c.stdout, id)
+		}
+	}
+
+	if err := c.forget(ctx, deleted); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, deleted)
+	}
+
+	fmt.Fprintf(c.stderr, "deleted %d documents\n", len(deleted))
+	return nil
+}
+
+// forget drops deleted documents from the local cache, and from the mirror
+// when there is one, so that completion no longer offers them.
+func (c *deleteCmd) forget(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := c.store().Delete(ctx, ids); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(c.mirrorPath()); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	mirror, err := c.mirror(false)
+	if err != nil {
+		return err
+	}
+	defer mirror.Close()
+
+	return mirror.Delete(ctx, ids)
+}
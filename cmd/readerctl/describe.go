@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type describeCmd struct {
+	*rootCmd
+	format  string
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newDescribeCmd(root *rootCmd) *describeCmd {
+	cmd := &describeCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("describe").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.format, 0, "format", "", formatFlagHelp)
+	cmd.command = &ff.Command{
+		Name:      "describe",
+		Usage:     "readerctl describe [FLAGS] <ID>",
+		ShortHelp: "show everything about a document",
+		LongHelp:  "Highlights and notes are read from the local cache, run readerctl sync first.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *describeCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one document ID")
+	}
+
+	doc, err := c.fetchDocument(ctx, args[0], true)
+	if err != nil {
+		return err
+	}
+
+	children, err := c.cachedChildren(ctx, doc.ID)
+	if err != nil {
+		return err
+	}
+
+	var parent *readwisereader.Document
+	if doc.ParentID != "" {
+		parent, err = c.lookupDocument(ctx, doc.ParentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	format, err := parseFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format != nil {
+		return writeFormatted(c.stdout, format, doc)
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, map[string]any{
+			"document": doc,
+			"parent":   parent,
+			"children": children,
+		})
+	}
+
+	if c.output != outputTable {
+		return unsupportedOutput(c.output)
+	}
+
+	doc.HTMLContent = c.documentHTML(ctx, *doc)
+
+	return writeDescription(c.stdout, *doc, parent, children, c.wpm)
+}
+
+func writeDescription(w io.Writer, doc readwisereader.Document, parent *readwisereader.Document, children []readwisereader.Document, wpm int) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", name, value)
+		}
+	}
+
+	field("ID", doc.ID)
+	field("Title", doc.Title)
+	field("Author", doc.Author)
+	field("Site", doc.SiteName)
+	field("URL", documentLink(doc))
+	field("Reader URL", doc.URL)
+	field("Category", string(doc.Category))
+	field("Location", string(doc.Location))
+	if doc.WordCount > 0 {
+		field("Words", fmt.Sprintf("%d (%s)", doc.WordCount, formatReadingTime(readingTime(doc.WordCount, wpm))))
+	}
+	field("Published", formatDate(doc.PublishedDate))
+	field("Saved", formatDate(doc.SavedAt))
+	field("Last opened", formatDate(doc.LastOpenedAt))
+	field("Progress", progressBar(doc.ReadingProgress, 20))
+	field("Tags", strings.Join(slices.Sorted(maps.Keys(doc.Tags)), ", "))
+	if parent != nil {
+		field("Parent", fmt.Sprintf("%s (%s)", parent.ID, parent.Title))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	section := func(title, body string) {
+		if body = strings.TrimSpace(body); body != "" {
+			fmt.Fprintf(w, "\n%s\n  %s\n", title, strings.ReplaceAll(body, "\n", "\n  "))
+		}
+	}
+
+	section("Summary", doc.Summary)
+	section("Notes", doc.Notes)
+
+	if len(children) > 0 {
+		fmt.Fprintf(w, "\nHighlights (%d)\n", len(children))
+		for _, child := range children {
+			fmt.Fprintf(w, "  %s  %s\n", child.ID, truncate(strings.Join(strings.Fields(child.Content), " "), 72))
+		}
+	}
+
+	section("Content", firstParagraph(doc.HTMLContent))
+	return nil
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Local().Format(time.DateOnly)
+}
+
+func progressBar(progress float64, width int) string {
+	progress = min(max(progress, 0), 1)
+	filled := int(progress * float64(width))
+	return fmt.Sprintf("[%s%s] %.0f%%", strings.Repeat("#", filled), strings.Repeat("-", width-filled), progress*100)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-1]) + "…"
+}
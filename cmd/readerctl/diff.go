@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// maxDiffLines bounds the input of writeLineDiff, the diff takes quadratic time
+// and memory.
+const maxDiffLines = 2000
+
+// writeLineDiff writes the lines removed from a and added in b, prefixed with
+// - and +, skipping lines both have in common. It reports false without
+// writing anything when the inputs are too large to diff.
+func writeLineDiff(w io.Writer, a, b []string) bool {
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return false
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(w, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(w, "+ %s\n", b[j])
+			j++
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"slices"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/readability"
+)
+
+// lookupDocument returns the document with the given ID, preferring the local
+// cache and falling back to the API.
+func (r *rootCmd) lookupDocument(ctx context.Context, id string) (*readwisereader.Document, error) {
+	doc, err := r.store().Document(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if doc != nil {
+		return doc, nil
+	}
+
+	return r.fetchDocument(ctx, id, false)
+}
+
+// fetchDocument returns the document with the given ID from the API.
+func (r *rootCmd) fetchDocument(ctx context.Context, id string, withHTML bool) (*readwisereader.Document, error) {
+	client, err := r.client()
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []readwisereader.GetOption
+	if withHTML {
+		opts = append(opts, readwisereader.IncludeHTMLContent())
+	}
+
+	return client.Get(ctx, id, opts...)
+}
+
+// cachedDocuments iterates over the documents in the local cache.
+func (r *rootCmd) cachedDocuments(ctx context.Context) iter.Seq2[readwisereader.Document, error] {
+	return func(yield func(readwisereader.Document, error) bool) {
+		docs, err := r.store().Documents(ctx)
+		if err != nil {
+			yield(readwisereader.Document{}, err)
+			return
+		}
+
+		for _, doc := range docs {
+			if !yield(doc, nil) {
+				return
+			}
+		}
+	}
+}
+
+// cachedChildren returns the highlights and notes of a document from the local
+// cache, oldest first. The API has no way to filter by parent.
+func (r *rootCmd) cachedChildren(ctx context.Context, id string) ([]readwisereader.Document, error) {
+	docs, err := r.store().Documents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []readwisereader.Document
+	for _, doc := range docs {
+		if doc.ParentID == id {
+			children = append(children, doc)
+		}
+	}
+
+	slices.SortFunc(children, func(a, b readwisereader.Document) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return children, nil
+}
+
+// documentHTML returns the HTML content of doc. With --extract, documents
+// without content get it extracted from their source page instead.
+func (r *rootCmd) documentHTML(ctx context.Context, doc readwisereader.Document) string {
+	if doc.HTMLContent != "" || !r.extract {
+		return doc.HTMLContent
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	content, err := readability.Fetch(ctx, client, documentLink(doc))
+	if err != nil {
+		fmt.Fprintf(r.stderr, "extract %s: %v\n", doc.ID, err)
+		return ""
+	}
+
+	return content
+}
+
+// documentSeq yields docs in the shape library iterators take.
+func documentSeq(docs []readwisereader.Document) iter.Seq2[readwisereader.Document, error] {
+	return func(yield func(readwisereader.Document, error) bool) {
+		for _, doc := range docs {
+			if !yield(doc, nil) {
+				return
+			}
+		}
+	}
+}
+
+func documentLink(doc readwisereader.Document) string {
+	if doc.SourceURL != "" {
+		return doc.SourceURL
+	}
+
+	return doc.URL
+}
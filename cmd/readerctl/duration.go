@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseDuration is time.ParseDuration with support for whole day (d) and
+// week (w) units, which are the ones people actually use on the command line.
+func parseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	} {
+		n, ok := strings.CutSuffix(s, suffix)
+		if !ok {
+			continue
+		}
+
+		v, err := strconv.Atoi(n)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+
+		return time.Duration(v) * unit, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// parseTime parses a point in time the way it is typed on the command line:
+// RFC 3339, a date, a duration ago such as 36h or 2w, or now, today,
+// yesterday or the name of a weekday, the last one up to and including
+// today. Days start at midnight in the local time zone.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch key := strings.ToLower(s); key {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	default:
+		if day, ok := weekdays[key]; ok {
+			back := (int(now.Weekday()) - int(day) + 7) % 7
+			return midnight.AddDate(0, 0, -back), nil
+		}
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	d, err := parseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected a date, a duration such as 36h or 2w, today, yesterday or a weekday", s)
+	}
+
+	return now.Add(-d), nil
+}
+
+// timeValue is a flag.Value for flags taking a point in time, see parseTime.
+type timeValue struct {
+	time.Time
+	raw string
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := parseTime(s, time.Now())
+	if err != nil {
+		return err
+	}
+
+	v.Time, v.raw = t, s
+	return nil
+}
+
+func (v *timeValue) String() string {
+	return v.raw
+}
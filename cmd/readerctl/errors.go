@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// exitError makes readerctl exit with a specific code, optionally reporting err.
+type exitError struct {
+	code int
+	err  error
+}
+
+func exitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.code)
+	}
+
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// usageError marks errors caused by how readerctl was invoked.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+type jsonError struct {
+	Type              string  `json:"type"`
+	Message           string  `json:"message"`
+	RetryAfterSeconds float64 `json:"retry_after_seconds,omitempty"`
+	HTTPStatus        int     `json:"http_status,omitempty"`
+}
+
+// reportError writes err to stderr, as JSON with --output json, and returns
+// the exit code to use.
+func (r *rootCmd) reportError(err error) int {
+	code := 1
+
+	var ee *exitError
+	if errors.As(err, &ee) {
+		code = ee.code
+		err = ee.err
+	}
+
+	if err == nil {
+		return code
+	}
+
+	if r.output != outputJSON {
+		fmt.Fprintf(r.stderr, "error: %v\n", err)
+		return code
+	}
+
+	je := jsonError{
+		Type:    "error",
+		Message: err.Error(),
+	}
+
+	var (
+		ue  *usageError
+		rle *readwisereader.ErrorRateLimited
+		ae  *readwisereader.APIError
+	)
+	switch {
+	case errors.As(err, &ue):
+		je.Type = "usage"
+	case errors.Is(err, errNoToken):
+		je.Type = "auth"
+	case errors.As(err, &rle):
+		je.Type = "rate_limited"
+		je.RetryAfterSeconds = rle.RetryAfter.Seconds()
+		je.HTTPStatus = 429
+	case errors.As(err, &ae):
+		je.Type = "api"
+		if errors.Is(err, readwisereader.ErrUnauthorized) {
+			je.Type = "auth"
+		}
+		je.HTTPStatus = ae.StatusCode
+	case errors.Is(err, context.Canceled):
+		je.Type = "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		je.Type = "timeout"
+	}
+
+	if err := json.NewEncoder(r.stderr).Encode(je); err != nil {
+		fmt.Fprintf(r.stderr, "error: %v\n", je.Message)
+	}
+
+	return code
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// exitNotFound is the exit code for lookups that found nothing, distinct from
+// the generic failure code.
+const exitNotFound = 4
+
+type existsCmd struct {
+	*rootCmd
+	cached  bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newExistsCmd(root *rootCmd) *existsCmd {
+	cmd := &existsCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("exists").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.cached, 0, "cached", "only consult the local cache")
+	cmd.command = &ff.Command{
+		Name:      "exists",
+		Usage:     "readerctl exists [FLAGS] <URL>",
+		ShortHelp: "check whether a URL is already saved",
+		LongHelp: `Prints the document ID and location and exits 0 when the URL is saved,
+exits 4 when it isn't.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *existsCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one URL")
+	}
+
+	doc, err := c.findByURL(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if doc == nil {
+		return exitCode(exitNotFound, nil)
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, map[string]any{
+			"id":       doc.ID,
+			"location": doc.Location,
+		})
+	}
+
+	fmt.Fprintf(c.stdout, "%s\t%s\n", doc.ID, doc.Location)
+	return nil
+}
+
+func (c *existsCmd) findByURL(ctx context.Context, u string) (*readwisereader.Document, error) {
+	docs, err := c.store().Documents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		if doc.ParentID == "" && doc.HasURL(u) {
+			return &doc, nil
+		}
+	}
+
+	if c.cached {
+		return nil, nil
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.ExistsByURL(ctx, u)
+}
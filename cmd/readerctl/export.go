@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/warc"
+	"github.com/peterbourgon/ff/v4"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPageAssets bounds the number of assets archived per page.
+const maxPageAssets = 50
+
+type exportCmd struct {
+	*rootCmd
+	format     string
+	file       string
+	assets     bool
+	noFetch    bool
+	checkpoint string
+	shards     int
+	dir        string
+	withHTML   bool
+
+	updatedAfter timeValue
+
+	// progress shows how far the listing being exported got.
+	progress *listProgress
+
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newExportCmd(root *rootCmd) *exportCmd {
+	cmd := &exportCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("export").SetParent(root.flags)
+	cmd.flags.StringEnumVar(&cmd.format, 0, "format", "export format", "warc", "md", "json", "html")
+	cmd.flags.StringVar(&cmd.file, 0, "file", "", "output file for single file formats, compressed if it ends in .gz")
+	cmd.flags.StringVar(&cmd.dir, 0, "dir", "", "output directory for the md, json and html formats, one file per document")
+	cmd.flags.BoolVar(&cmd.withHTML, 0, "with-html", "include the html content of documents in md and json exports")
+	cmd.flags.Value(0, "updated-after", &cmd.updatedAfter, "only export documents updated after this time, for incremental exports, e.g. 7d, yesterday or 2024-05-01")
+	cmd.flags.BoolVar(&cmd.assets, 0, "assets", "also archive images, stylesheets and scripts of fetched pages")
+	cmd.flags.BoolVar(&cmd.noFetch, 0, "no-fetch", "only archive the content stored in Reader, don't fetch source pages")
+	cmd.flags.StringVar(&cmd.checkpoint, 0, "checkpoint", "", "record progress in this file and resume from it when it exists")
+	cmd.flags.IntVar(&cmd.shards, 0, "shards", 1, "list the library in this many concurrent time windows")
+	cmd.command = &ff.Command{
+		Name:      "export",
+		Usage:     "readerctl export [FLAGS]",
+		ShortHelp: "export documents",
+		LongHelp: `With --format warc, every document's Reader content is written as a
+resource record, and its source page is fetched and written as a
+request/response pair.
+
+With --format md, json or html, every document is written to a file of its
+own in --dir, named by ID and title, with its metadata as front matter, in
+the JSON object or in meta elements. Exporting again overwrites the files of
+documents exported before.
+
+With --checkpoint, an interrupted export appends to --file where it stopped
+when run again with the same flags. Directory exports always keep a
+checkpoint, in the directory unless --checkpoint names another file.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *exportCmd) exec(ctx context.Context, args []string) error {
+	if c.format != "warc" {
+		if c.dir == "" {
+			return errors.New("--dir is required")
+		}
+
+		client, err := c.client()
+		if err != nil {
+			return err
+		}
+
+		return c.exportDir(ctx, client)
+	}
+
+	if c.dir != "" {
+		return errors.New("--dir takes --format md, json or html")
+	}
+
+	if c.file == "" {
+		return errors.New("--file is required")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	cp, err := loadCheckpoint(c.checkpoint)
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if !cp.resumed() {
+		flags |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(c.file, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	out := &countingWriter{w: f}
+	if cp.resumed() {
+		// WARC records, compressed ones included, can simply be appended
+		// once the ones written after the checkpoint are dropped.
+		if err := f.Truncate(cp.Offset); err != nil {
+			return err
+		}
+		if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+			return err
+		}
+		out.n = cp.Offset
+		fmt.Fprintf(c.stderr, "resuming export, %d documents already exported\n", len(cp.Processed))
+	}
+	cp.track(out)
+
+	if err := c.exportWARC(ctx, client, out, cp); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return cp.remove()
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func (c *exportCmd) exportWARC(ctx context.Context, client *readwisereader.Client, w io.Writer, cp *checkpoint) error {
+	ww := warc.NewWriter(w, strings.HasSuffix(c.file, ".gz"))
+	if !cp.resumed() {
+		if _, err := ww.WriteWarcinfo(map[string]string{
+			"software": "readerctl",
+			"format":   "WARC File Format 1.1",
+		}); err != nil {
+			return err
+		}
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	archived := map[string]bool{}
+
+	var count int
+	params := readwisereader.ListParams{UpdatedAfter: c.updatedAfter.Time, WithHTMLContent: true}
+	if cp != nil {
+		params.PageCursor = cp.Cursor
+	}
+
+	for {
+		err := c.exportPages(ctx, client, params, cp, func(doc readwisereader.Document) error {
+			if cp.done(doc.ID) {
+				return nil
+			}
+
+			if err := c.exportDocument(ctx, ww, httpClient, doc, archived); err != nil {
+				return err
+			}
+
+			count++
+			return cp.record(doc.ID)
+		})
+
+		// Cursors don't live forever, start over and skip what is done.
+		if errors.Is(err, readwisereader.ErrInvalidCursor) && params.PageCursor != "" {
+			fmt.Fprintln(c.stderr, "checkpoint cursor expired, listing from the start")
+			params.PageCursor = ""
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		break
+	}
+
+	fmt.Fprintf(c.stderr, "exported %d documents\n", count)
+	return nil
+}
+
+// exportPages calls fn for every document listed with params, other than
+// highlights and notes, recording each page in cp before it is processed.
+// Sharded listings have no pages to resume from, only the processed
+// documents are recorded for them.
+func (c *exportCmd) exportPages(ctx context.Context, client *readwisereader.Client, params readwisereader.ListParams, cp *checkpoint, fn func(readwisereader.Document) error) error {
+	c.progress = c.listProgress("exporting")
+	c.progress.track(&params)
+	defer c.progress.clear()
+
+	if c.shards > 1 {
+		for doc, err := range c.progress.counted(client.ListSharded(ctx, params, c.shards)) {
+			if err != nil {
+				return err
+			}
+
+			if doc.ParentID != "" {
+				continue
+			}
+
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for page, err := range client.ListPaginate(ctx, params) {
+		if err != nil {
+			return err
+		}
+
+		if err := cp.page(page.Cursor); err != nil {
+			return err
+		}
+		c.progress.page(page)
+
+		for _, doc := range page.Results {
+			if doc.ParentID != "" {
+				continue
+			}
+
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *exportCmd) exportDocument(ctx context.Context, ww *warc.Writer, client *http.Client, doc readwisereader.Document, archived map[string]bool) error {
+	if doc.HTMLContent != "" {
+		_, err := ww.Write(warc.Record{
+			Type:        warc.TypeResource,
+			TargetURI:   doc.URL,
+			Date:        doc.UpdatedAt,
+			ContentType: "text/html; charset=utf-8",
+			Block:       []byte(doc.HTMLContent),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !c.noFetch && doc.SourceURL != "" {
+		if err := c.archiveURL(ctx, ww, client, doc.SourceURL, c.assets, archived); err != nil {
+			c.progress.clear()
+			fmt.Fprintf(c.stderr, "%s: %v\n", doc.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// archiveURL fetches u into the archive, followed by its assets if asked to.
+func (c *exportCmd) archiveURL(ctx context.Context, ww *warc.Writer, client *http.Client, u string, assets bool, archived map[string]bool) error {
+	if archived[u] {
+		return nil
+	}
+	archived[u] = true
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := ww.WriteExchange(req, resp); err != nil {
+		return err
+	}
+
+	if !assets || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil
+	}
+
+	for _, asset := range pageAssets(body, resp.Request.URL) {
+		if err := c.archiveURL(ctx, ww, client, asset, false, archived); err != nil {
+			c.progress.clear()
+			fmt.Fprintf(c.stderr, "%s: %v\n", asset, err)
+		}
+	}
+
+	return nil
+}
+
+// pageAssets returns the absolute URLs of images, stylesheets and scripts
+// referenced by an HTML page.
+func pageAssets(body []byte, base *url.URL) []string {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var assets []string
+	for n := range doc.Descendants() {
+		if len(assets) >= maxPageAssets {
+			break
+		}
+
+		if n.Type != html.ElementNode {
+			continue
+		}
+
+		var ref string
+		switch n.DataAtom {
+		case atom.Img, atom.Script:
+			ref = htmlAttr(n, "src")
+		case atom.Link:
+			if strings.EqualFold(htmlAttr(n, "rel"), "stylesheet") {
+				ref = htmlAttr(n, "href")
+			}
+		}
+
+		if ref == "" || strings.HasPrefix(ref, "data:") {
+			continue
+		}
+
+		if u, err := base.Parse(ref); err == nil {
+			assets = append(assets, u.String())
+		}
+	}
+
+	return assets
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
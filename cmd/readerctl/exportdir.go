@@ -0,0 +1,210 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// exportCheckpointFile is the checkpoint a directory export keeps in the
+// directory, unless --checkpoint names another.
+const exportCheckpointFile = ".readerctl-export.json"
+
+// exportDir writes every document to a file of its own in --dir.
+func (c *exportCmd) exportDir(ctx context.Context, client *readwisereader.Client) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	path := c.checkpoint
+	if path == "" {
+		path = filepath.Join(c.dir, exportCheckpointFile)
+	}
+
+	cp, err := loadCheckpoint(path)
+	if err != nil {
+		return err
+	}
+
+	if cp.resumed() {
+		fmt.Fprintf(c.stderr, "resuming export, %d documents already exported\n", len(cp.Processed))
+	}
+
+	ext := "." + c.format
+	params := readwisereader.ListParams{
+		UpdatedAfter:    c.updatedAfter.Time,
+		WithHTMLContent: c.withHTML || c.format == "html",
+		PageCursor:      cp.Cursor,
+	}
+
+	var count int
+	for {
+		err := c.exportPages(ctx, client, params, cp, func(doc readwisereader.Document) error {
+			if cp.done(doc.ID) {
+				return nil
+			}
+
+			if err := c.writeDocumentFile(doc, ext); err != nil {
+				return err
+			}
+
+			count++
+			return cp.record(doc.ID)
+		})
+
+		// Cursors don't live forever, start over and skip what is done.
+		if errors.Is(err, readwisereader.ErrInvalidCursor) && params.PageCursor != "" {
+			fmt.Fprintln(c.stderr, "checkpoint cursor expired, listing from the start")
+			params.PageCursor = ""
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		break
+	}
+
+	fmt.Fprintf(c.stderr, "exported %d documents to %s\n", count, c.dir)
+	return cp.remove()
+}
+
+// writeDocumentFile writes doc to --dir, replacing an earlier export of it
+// named after an older title.
+func (c *exportCmd) writeDocumentFile(doc readwisereader.Document, ext string) error {
+	var b []byte
+	switch c.format {
+	case "md":
+		b = []byte(documentMarkdown(doc))
+	case "html":
+		b = []byte(documentHTMLPage(doc))
+	case "json":
+		var sb strings.Builder
+		if err := writeJSON(&sb, doc); err != nil {
+			return err
+		}
+		b = []byte(sb.String())
+	}
+
+	name := documentFileName(doc.ID, doc.Title, ext)
+	earlier, err := filepath.Glob(filepath.Join(c.dir, doc.ID+"*"+ext))
+	if err != nil {
+		return err
+	}
+	for _, path := range earlier {
+		base := filepath.Base(path)
+		if base != name && (base == doc.ID+ext || strings.HasPrefix(base, doc.ID+"-")) {
+			os.Remove(path)
+		}
+	}
+
+	return os.WriteFile(filepath.Join(c.dir, name), b, 0o644)
+}
+
+// documentMarkdown renders doc as Markdown with its metadata as YAML front
+// matter, followed by the summary, notes and content.
+func documentMarkdown(doc readwisereader.Document) string {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	for _, field := range documentMetadata(doc) {
+		fmt.Fprintf(&b, "%s: %s\n", field.name, strconv.Quote(field.value))
+	}
+	if tags := documentTags(doc); len(tags) > 0 {
+		quoted := make([]string, len(tags))
+		for i, tag := range tags {
+			quoted[i] = strconv.Quote(tag)
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(quoted, ", "))
+	}
+	if doc.WordCount > 0 {
+		fmt.Fprintf(&b, "word_count: %d\n", doc.WordCount)
+	}
+	fmt.Fprintf(&b, "reading_progress: %g\n", doc.ReadingProgress)
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n", cmp.Or(doc.Title, doc.ID))
+
+	if summary := strings.TrimSpace(doc.Summary); summary != "" {
+		fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(summary, "\n", "\n> "))
+	}
+
+	if notes := strings.TrimSpace(doc.Notes); notes != "" {
+		fmt.Fprintf(&b, "\n## Notes\n\n%s\n", notes)
+	}
+
+	if content := htmlMarkdown(doc.HTMLContent); content != "" {
+		fmt.Fprintf(&b, "\n%s\n", content)
+	}
+
+	return b.String()
+}
+
+// documentHTMLPage renders doc as a standalone HTML page with its metadata
+// in meta elements.
+func documentHTMLPage(doc readwisereader.Document) string {
+	var b strings.Builder
+
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(cmp.Or(doc.Title, doc.ID)))
+	for _, field := range documentMetadata(doc) {
+		fmt.Fprintf(&b, "<meta name=\"readwise:%s\" content=\"%s\">\n", field.name, html.EscapeString(field.value))
+	}
+	if tags := documentTags(doc); len(tags) > 0 {
+		fmt.Fprintf(&b, "<meta name=\"readwise:tags\" content=\"%s\">\n", html.EscapeString(strings.Join(tags, ", ")))
+	}
+	b.WriteString("</head>\n<body>\n")
+
+	content := doc.HTMLContent
+	if content == "" {
+		content = "<p>" + html.EscapeString(doc.Summary) + "</p>"
+	}
+	b.WriteString(content)
+
+	b.WriteString("\n</body>\n</html>\n")
+	return b.String()
+}
+
+type metadataField struct {
+	name  string
+	value string
+}
+
+// documentMetadata lists the non-empty metadata of doc exported with it.
+func documentMetadata(doc readwisereader.Document) []metadataField {
+	timestamp := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	all := []metadataField{
+		{"id", doc.ID},
+		{"title", doc.Title},
+		{"author", doc.Author},
+		{"site_name", doc.SiteName},
+		{"url", doc.URL},
+		{"source_url", doc.SourceURL},
+		{"category", string(doc.Category)},
+		{"location", string(doc.Location)},
+		{"published_date", timestamp(doc.PublishedDate)},
+		{"saved_at", timestamp(doc.SavedAt)},
+		{"updated_at", timestamp(doc.UpdatedAt)},
+	}
+
+	return slices.DeleteFunc(all, func(f metadataField) bool {
+		return f.value == ""
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type feedsCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newFeedsCmd(root *rootCmd) *feedsCmd {
+	cmd := &feedsCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("feeds").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "feeds",
+		Usage:     "readerctl feeds <SUBCOMMAND> ...",
+		ShortHelp: "manage RSS subscriptions",
+		Flags:     cmd.flags,
+	}
+
+	newFeedsImportCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type feedsImportCmd struct {
+	*feedsCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newFeedsImportCmd(parent *feedsCmd) *feedsImportCmd {
+	cmd := &feedsImportCmd{feedsCmd: parent}
+	cmd.flags = ff.NewFlagSet("import").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "import",
+		Usage:     "readerctl feeds import [FLAGS] <FILE.opml>",
+		ShortHelp: "subscribe to every feed in an OPML file",
+		LongHelp: `Feeds listed more than once in the file are subscribed to once, and feeds
+Reader reports as already saved are counted as skipped.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *feedsImportCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one OPML file")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	feeds, err := parseOPML(f)
+	if err != nil {
+		return fmt.Errorf("parse opml: %w", err)
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	// The API has no way to list subscribed feeds, documents only carry
+	// the URLs of feed items. Duplicates within the file are dropped here,
+	// feeds subscribed before are reported by the save itself.
+	seen := map[string]bool{}
+	var batch []readwisereader.SaveParams
+	var skipped int
+	for _, feed := range feeds {
+		key := readwisereader.NormalizeURL(feed.URL)
+		if seen[key] {
+			skipped++
+			fmt.Fprintf(c.stderr, "skipped %s\n", feed.URL)
+			continue
+		}
+		seen[key] = true
+
+		params := readwisereader.SaveParams{
+			URL:      feed.URL,
+			Category: readwisereader.CategoryRSS,
+		}
+		if feed.Title != "" {
+			params.Title = &feed.Title
+		}
+		batch = append(batch, params)
+	}
+
+	var done, subscribed, failed int
+	_, err = client.SaveBatch(ctx, batch, readwisereader.OnSaved(func(r readwisereader.SaveResult) {
+		done++
+		progress := fmt.Sprintf("[%d/%d]", done, len(batch))
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(c.stderr, "%s %s: %v\n", progress, r.Params.URL, r.Err)
+			return
+		}
+
+		if r.Response.AlreadyExists {
+			skipped++
+			fmt.Fprintf(c.stderr, "%s skipped %s\n", progress, r.Params.URL)
+			return
+		}
+
+		subscribed++
+		fmt.Fprintf(c.stderr, "%s subscribed %s\n", progress, r.Params.URL)
+	}))
+	fmt.Fprintf(c.stderr, "subscribed to %d feeds, skipped %d, failed %d\n", subscribed, skipped, failed)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d subscriptions failed", failed, len(batch))
+	}
+
+	return nil
+}
+
+type opmlFeed struct {
+	Title string
+	URL   string
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func parseOPML(r io.Reader) ([]opmlFeed, error) {
+	var doc struct {
+		Body struct {
+			Outlines []opmlOutline `xml:"outline"`
+		} `xml:"body"`
+	}
+
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var feeds []opmlFeed
+	var walk func([]opmlOutline)
+	walk = func(outlines []opmlOutline) {
+		for _, o := range outlines {
+			if o.XMLURL != "" {
+				title := o.Title
+				if title == "" {
+					title = o.Text
+				}
+				feeds = append(feeds, opmlFeed{Title: title, URL: o.XMLURL})
+			}
+
+			// Folders are outlines nesting other outlines.
+			walk(o.Outlines)
+		}
+	}
+	walk(doc.Body.Outlines)
+
+	return feeds, nil
+}
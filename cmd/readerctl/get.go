@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type getCmd struct {
+	*rootCmd
+	format  string
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newGetCmd(root *rootCmd) *getCmd {
+	cmd := &getCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("get").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.format, 0, "format", "", formatFlagHelp)
+	cmd.command = &ff.Command{
+		Name:      "get",
+		Usage:     "readerctl get [FLAGS] [<ID>...]",
+		ShortHelp: "print documents by ID",
+		LongHelp:  "Without IDs, or with -, IDs are read from stdin, one per line.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *getCmd) exec(ctx context.Context, args []string) error {
+	if !isTabular(c.output) && c.output != outputJSON && c.output != outputJSONL {
+		return unsupportedOutput(c.output)
+	}
+
+	format, err := parseFormat(c.format)
+	if err != nil {
+		return err
+	}
+
+	ids, err := documentIDs(args, c.stdin)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	results, err := client.GetMany(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	var docs []readwisereader.Document
+	var failed int
+	for _, id := range ids {
+		r := results[id]
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(c.stderr, "get %s: %v\n", id, r.Err)
+			continue
+		}
+		docs = append(docs, r.Document)
+	}
+
+	if err := c.writeDocuments(docs, format); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d documents could not be fetched", failed, len(ids))
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type goals struct {
+	ArticlesPerWeek int `json:"articles_per_week"`
+}
+
+type goalsCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newGoalsCmd(root *rootCmd) *goalsCmd {
+	cmd := &goalsCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("goals").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "goals",
+		Usage:     "readerctl goals <SUBCOMMAND> ...",
+		ShortHelp: "set and track reading goals",
+		Flags:     cmd.flags,
+	}
+
+	newGoalsSetCmd(cmd)
+	newGoalsStatusCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *goalsCmd) loadGoals() (goals, error) {
+	var g goals
+	err := readJSONFile(c.stateFile("goals.json"), &g)
+	return g, err
+}
+
+func (c *goalsCmd) saveGoals(g goals) error {
+	return writeJSONFile(c.stateFile("goals.json"), g)
+}
+
+type goalsSetCmd struct {
+	*goalsCmd
+	articlesPerWeek int
+	flags           *ff.FlagSet
+	command         *ff.Command
+}
+
+func newGoalsSetCmd(parent *goalsCmd) *goalsSetCmd {
+	cmd := &goalsSetCmd{goalsCmd: parent}
+	cmd.flags = ff.NewFlagSet("set").SetParent(parent.flags)
+	cmd.flags.IntVar(&cmd.articlesPerWeek, 0, "articles-per-week", 0, "number of articles to finish every week")
+	cmd.command = &ff.Command{
+		Name:      "set",
+		Usage:     "readerctl goals set [FLAGS]",
+		ShortHelp: "set reading goals",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *goalsSetCmd) exec(ctx context.Context, args []string) error {
+	if c.articlesPerWeek <= 0 {
+		return errors.New("--articles-per-week must be positive")
+	}
+
+	g, err := c.loadGoals()
+	if err != nil {
+		return err
+	}
+
+	g.ArticlesPerWeek = c.articlesPerWeek
+	return c.saveGoals(g)
+}
+
+type goalsStatusCmd struct {
+	*goalsCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newGoalsStatusCmd(parent *goalsCmd) *goalsStatusCmd {
+	cmd := &goalsStatusCmd{goalsCmd: parent}
+	cmd.flags = ff.NewFlagSet("status").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "status",
+		Usage:     "readerctl goals status [FLAGS]",
+		ShortHelp: "show progress towards reading goals",
+		LongHelp: `Counts the articles archived or read through since Monday, from the
+changes readerctl sync recorded.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *goalsStatusCmd) exec(ctx context.Context, args []string) error {
+	g, err := c.loadGoals()
+	if err != nil {
+		return err
+	}
+
+	if g.ArticlesPerWeek == 0 {
+		return errors.New("no goals set, see readerctl goals set")
+	}
+
+	now := time.Now()
+	weekStart := startOfWeek(now)
+
+	store := c.store()
+	transitions, err := store.Transitions(ctx, weekStart)
+	if err != nil {
+		return err
+	}
+
+	var finished int
+	counted := map[string]bool{}
+	for _, t := range transitions {
+		if counted[t.DocumentID] || !finishes(t) {
+			continue
+		}
+		counted[t.DocumentID] = true
+
+		// Only articles count, not highlights, notes, books or videos.
+		doc, err := store.Document(ctx, t.DocumentID)
+		if err != nil {
+			return err
+		}
+		if doc == nil || doc.ParentID != "" || doc.Category != readwisereader.CategoryArticle {
+			continue
+		}
+
+		finished++
+	}
+
+	daysLeft := int(weekStart.AddDate(0, 0, 7).Sub(now).Hours() / 24)
+	percent := float64(finished) / float64(g.ArticlesPerWeek) * 100
+
+	fmt.Fprintf(c.stdout, "this week: %d of %d articles (%.0f%%), %d days left\n", finished, g.ArticlesPerWeek, percent, daysLeft)
+	return nil
+}
+
+// finishes reports whether the transition finished its document, either by
+// archiving it or by reading it through.
+func finishes(t sync.Transition) bool {
+	if t.ToLocation == readwisereader.LocationArchive && t.FromLocation != readwisereader.LocationArchive {
+		return true
+	}
+
+	return t.FromProgress < completedProgress && t.ToProgress >= completedProgress
+}
+
+// startOfWeek returns midnight of the Monday of the week t falls into.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
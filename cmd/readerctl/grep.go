@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type grepCmd struct {
+	*rootCmd
+	ignoreCase bool
+	notesDir   string
+	flags      *ff.FlagSet
+	command    *ff.Command
+}
+
+func newGrepCmd(root *rootCmd) *grepCmd {
+	cmd := &grepCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("grep").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.ignoreCase, 'i', "ignore-case", "match case insensitively")
+	cmd.flags.StringVar(&cmd.notesDir, 0, "notes-dir", "", "directory of exported markdown notes, named by ID and title, that quickfix output points at")
+	cmd.command = &ff.Command{
+		Name:      "grep",
+		Usage:     "readerctl grep [FLAGS] <PATTERN>",
+		ShortHelp: "search cached titles, summaries, notes and highlights",
+		LongHelp: "PATTERN is a regular expression. Matches in highlights and notes are " +
+			"reported against the document they belong to. " +
+			"With --output quickfix every match is printed as file:line:title: text, " +
+			"pointing into --notes-dir, for the quickfix lists of Vim and Emacs.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type grepMatch struct {
+	Document readwisereader.Document `json:"document"`
+	// Field of the document, or of one of its highlights, that matched
+	Field string `json:"field"`
+	Text  string `json:"text"`
+}
+
+func (c *grepCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected a single pattern")
+	}
+
+	switch c.output {
+	case outputTable, outputCSV, outputTSV, outputJSON:
+	case outputQuickfix:
+		if c.notesDir == "" {
+			return errors.New("--output quickfix requires --notes-dir")
+		}
+	default:
+		return unsupportedOutput(c.output)
+	}
+
+	pattern := args[0]
+	if c.ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	docs, err := c.store().Documents(ctx)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]readwisereader.Document, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc
+	}
+
+	var matches []grepMatch
+	for _, doc := range docs {
+		fields := []struct{ name, text string }{
+			{"title", doc.Title},
+			{"summary", doc.Summary},
+			{"notes", doc.Notes},
+			{"content", doc.Content},
+		}
+
+		owner := doc
+		if doc.ParentID != "" {
+			parent, ok := byID[doc.ParentID]
+			if !ok {
+				continue
+			}
+			owner = parent
+			fields = fields[2:]
+		}
+
+		for _, f := range fields {
+			if f.text != "" && re.MatchString(f.text) {
+				field := f.name
+				if owner.ID != doc.ID {
+					field = "highlight " + f.name
+				}
+				matches = append(matches, grepMatch{Document: owner, Field: field, Text: f.text})
+			}
+		}
+	}
+
+	switch c.output {
+	case outputJSON:
+		return writeJSON(c.stdout, matches)
+	case outputQuickfix:
+		for _, m := range matches {
+			path := filepath.Join(c.notesDir, documentFileName(m.Document.ID, m.Document.Title, ".md"))
+			fmt.Fprintf(c.stdout, "%s:%d:%s: %s\n", path, matchLine(path, re), oneLine(m.Document.Title), oneLine(m.Text))
+		}
+		return nil
+	default:
+		t := newTable("ID", "TITLE", "FIELD", "MATCH")
+		for _, m := range matches {
+			t.add(m.Document.ID, m.Document.Title, m.Field, oneLine(m.Text))
+		}
+		return c.writeTable(t)
+	}
+}
+
+// matchLine returns the first line of the file at path matching re, or 1 if
+// the file can't be read or there is no match, so editors still open it.
+func matchLine(path string, re *regexp.Regexp) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 1
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for line := 1; s.Scan(); line++ {
+		if re.Match(s.Bytes()) {
+			return line
+		}
+	}
+
+	return 1
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
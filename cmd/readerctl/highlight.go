@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type highlightCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newHighlightCmd(root *rootCmd) *highlightCmd {
+	cmd := &highlightCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("highlight").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "highlight",
+		Usage:     "readerctl highlight <SUBCOMMAND> ...",
+		ShortHelp: "manage highlights",
+		Flags:     cmd.flags,
+	}
+
+	newHighlightAddCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type highlightAddCmd struct {
+	*highlightCmd
+	text    string
+	note    string
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newHighlightAddCmd(parent *highlightCmd) *highlightAddCmd {
+	cmd := &highlightAddCmd{highlightCmd: parent}
+	cmd.flags = ff.NewFlagSet("add").SetParent(parent.flags)
+	cmd.flags.StringVar(&cmd.text, 0, "text", "", "highlighted passage, - to read it from stdin")
+	cmd.flags.StringVar(&cmd.note, 0, "note", "", "note attached to the highlight")
+	cmd.command = &ff.Command{
+		Name:      "add",
+		Usage:     "readerctl highlight add [FLAGS] <ID>",
+		ShortHelp: "highlight a passage of a document",
+		LongHelp: `The highlight is created through the Readwise API, in the book matching the
+document's title, author and URL.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *highlightAddCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one document ID")
+	}
+
+	text := c.text
+	if text == "-" {
+		b, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("--text is required")
+	}
+
+	doc, err := c.lookupDocument(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	books, err := client.CreateHighlights(ctx, readwisereader.HighlightParams{
+		Text:       text,
+		Title:      doc.Title,
+		Author:     doc.Author,
+		Category:   highlightCategory(doc.Category),
+		SourceURL:  documentLink(*doc),
+		SourceType: "readerctl",
+		Note:       c.note,
+	})
+	if err != nil {
+		return fmt.Errorf("create highlight: %w", err)
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, books)
+	}
+
+	for _, book := range books {
+		for _, id := range book.HighlightIDs {
+			fmt.Fprintf(c.stdout, "%d\t%s\n", id, book.Title)
+		}
+	}
+
+	return nil
+}
+
+// highlightCategory maps a Reader category to the closest category of the
+// classic Readwise API.
+func highlightCategory(category readwisereader.Category) string {
+	switch category {
+	case readwisereader.CategoryEPUB:
+		return "books"
+	case readwisereader.CategoryTweet:
+		return "tweets"
+	default:
+		return "articles"
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// firstParagraph returns the text of the first non-empty <p> element.
+func firstParagraph(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return ""
+	}
+
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.Data != "p" {
+			continue
+		}
+
+		if text := nodeText(n); text != "" {
+			return text
+		}
+	}
+
+	return ""
+}
+
+// nodeText returns the text content of n with whitespace collapsed.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+			b.WriteString(" ")
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// blockTexts returns the text of the paragraphs, headings, list items and
+// other text blocks of s, in document order.
+func blockTexts(s string) []string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return nil
+	}
+
+	var texts []string
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+
+		switch n.Data {
+		case "p", "li", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "figcaption":
+			if text := nodeText(n); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+
+	return texts
+}
+
+// htmlMarkdown renders the text blocks of s as Markdown, keeping headings,
+// lists, quotes, code and links. Anything else is reduced to its text.
+func htmlMarkdown(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return ""
+	}
+
+	var blocks []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+
+			var block string
+			switch c.Data {
+			case "script", "style", "head":
+				continue
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				if text := inlineMarkdown(c); text != "" {
+					block = strings.Repeat("#", int(c.Data[1]-'0')) + " " + text
+				}
+			case "p", "figcaption":
+				block = inlineMarkdown(c)
+			case "li":
+				if text := inlineMarkdown(c); text != "" {
+					block = "- " + text
+				}
+			case "blockquote":
+				if text := inlineMarkdown(c); text != "" {
+					block = "> " + text
+				}
+			case "pre":
+				var b strings.Builder
+				for d := range c.Descendants() {
+					if d.Type == html.TextNode {
+						b.WriteString(d.Data)
+					}
+				}
+				if code := strings.Trim(b.String(), "\n"); code != "" {
+					block = "```\n" + code + "\n```"
+				}
+			default:
+				walk(c)
+				continue
+			}
+
+			if block != "" {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	walk(doc)
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// inlineMarkdown renders the text of n with links, emphasis and code spans,
+// whitespace collapsed.
+func inlineMarkdown(n *html.Node) string {
+	return strings.Join(strings.Fields(inlineMarkdownRaw(n)), " ")
+}
+
+func inlineMarkdownRaw(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			b.WriteString(c.Data)
+		case c.Type != html.ElementNode:
+		case c.Data == "br":
+			b.WriteString(" ")
+		case c.Data == "a" && htmlAttr(c, "href") != "":
+			b.WriteString(wrapMarkdown("[", inlineMarkdownRaw(c), "]("+htmlAttr(c, "href")+")"))
+		case c.Data == "strong" || c.Data == "b":
+			b.WriteString(wrapMarkdown("**", inlineMarkdownRaw(c), "**"))
+		case c.Data == "em" || c.Data == "i":
+			b.WriteString(wrapMarkdown("_", inlineMarkdownRaw(c), "_"))
+		case c.Data == "code":
+			b.WriteString(wrapMarkdown("`", nodeText(c), "`"))
+		default:
+			b.WriteString(inlineMarkdownRaw(c))
+		}
+	}
+
+	return b.String()
+}
+
+// wrapMarkdown puts s between open and close, keeping the whitespace around
+// it outside.
+func wrapMarkdown(open, s, close string) string {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return s
+	}
+
+	i := strings.Index(s, text)
+	return s[:i] + open + text + close + s[i+len(text):]
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	importPending = "pending"
+	importDone    = "done"
+	importFailed  = "failed"
+)
+
+// importManifest tracks every item of an import and how far it got, so that
+// an import can be resumed without saving anything twice.
+type importManifest struct {
+	Source string       `json:"source"`
+	Items  []importItem `json:"items"`
+
+	path string
+}
+
+type importItem struct {
+	Params readwisereader.SaveParams `json:"params"`
+	Status string                    `json:"status"`
+	// ID of the saved document, once done
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (m *importManifest) write() error {
+	return writeJSONFile(m.path, m)
+}
+
+type importCmd struct {
+	*rootCmd
+	manifest     string
+	resume       string
+	skipExisting bool
+	flags        *ff.FlagSet
+	command      *ff.Command
+}
+
+func newImportCmd(root *rootCmd) *importCmd {
+	cmd := &importCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("import").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.manifest, 0, "manifest", "", "file recording the status of every item, defaults to FILE.manifest.json")
+	cmd.flags.StringVar(&cmd.resume, 0, "resume", "", "resume the import recorded in this manifest, skipping items already saved")
+	cmd.flags.BoolVar(&cmd.skipExisting, 0, "skip-existing", "list the library first and skip URLs already in Reader instead of saving them again")
+	cmd.command = &ff.Command{
+		Name:      "import",
+		Usage:     "readerctl import [FLAGS] <FILE>",
+		ShortHelp: "save every URL listed in a file",
+		LongHelp: `FILE lists one URL per line, empty lines and lines starting with # are
+ignored. The subcommands import the exports of other read-it-later services.
+
+Progress is recorded in a manifest as the import goes. When an import stops
+partway, run readerctl import --resume MANIFEST to retry the items that
+failed or weren't reached, without FILE.
+
+With --skip-existing, URLs already in Reader are recorded as done without
+saving them, which would leave their location and tags as they are anyway
+but uses up the save rate limit.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	newImportPocketCmd(cmd)
+	newImportOmnivoreCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *importCmd) exec(ctx context.Context, args []string) error {
+	return c.importFile(ctx, args, readImportFile)
+}
+
+// importFile saves the items read from the file in args, or those left in
+// the manifest to resume with --resume.
+func (c *importCmd) importFile(ctx context.Context, args []string, read func(path string) ([]importItem, error)) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := c.loadManifest(args, read)
+	if err != nil {
+		return err
+	}
+
+	// Items found in Reader by --skip-existing are done before the loop.
+	var saved, existed, failed, skipped, found int
+	if c.skipExisting {
+		if found, err = c.markExisting(ctx, client, manifest); err != nil {
+			return err
+		}
+	}
+
+	// Imports are long, wait out rate limits rather than fail the rest.
+	ctx = readwisereader.WaitOutRateLimits(ctx)
+	for i := range manifest.Items {
+		item := &manifest.Items[i]
+		if item.Status == importDone {
+			skipped++
+			continue
+		}
+
+		resp, err := client.Save(ctx, item.Params)
+		if ctx.Err() != nil {
+			// Leave the item as it was, it is retried on resume either way.
+			break
+		}
+
+		if err != nil {
+			item.Status, item.Error = importFailed, err.Error()
+			fmt.Fprintf(c.stderr, "%s: %v\n", item.Params.URL, err)
+			failed++
+		} else {
+			item.Status, item.ID, item.Error = importDone, resp.ID, ""
+			if resp.AlreadyExists {
+				existed++
+			} else {
+				saved++
+			}
+		}
+
+		if err := manifest.write(); err != nil {
+			return fmt.Errorf("write manifest: %w", err)
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "saved %d, %d already in Reader, failed %d, skipped %d done earlier\n", saved, existed+found, failed, skipped-found)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("import interrupted, resume with --resume %s: %w", manifest.path, err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d items failed, retry them with --resume %s", failed, manifest.path)
+	}
+
+	return nil
+}
+
+// markExisting records the pending items whose URL is already in Reader as
+// done, listing the whole library once, and returns how many there were.
+func (c *importCmd) markExisting(ctx context.Context, client *readwisereader.Client, manifest *importManifest) (int, error) {
+	pending := map[string][]*importItem{}
+	for i := range manifest.Items {
+		if item := &manifest.Items[i]; item.Status != importDone {
+			u := readwisereader.NormalizeURL(item.Params.URL)
+			pending[u] = append(pending[u], item)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	progress := c.listProgress("looking for saved URLs")
+	params := readwisereader.ListParams{}
+	progress.track(&params)
+	defer progress.clear()
+
+	var existing int
+	for doc, err := range progress.documents(client.ListPaginate(ctx, params)) {
+		if err != nil {
+			return 0, fmt.Errorf("list saved documents: %w", err)
+		}
+		if doc.ParentID != "" {
+			continue
+		}
+
+		for _, u := range []string{doc.SourceURL, doc.URL} {
+			u = readwisereader.NormalizeURL(u)
+			for _, item := range pending[u] {
+				item.Status, item.ID, item.Error = importDone, doc.ID, ""
+				existing++
+			}
+			delete(pending, u)
+		}
+	}
+
+	if err := manifest.write(); err != nil {
+		return 0, fmt.Errorf("write manifest: %w", err)
+	}
+
+	return existing, nil
+}
+
+// loadManifest reads the manifest to resume with --resume, or starts a new one
+// from the file given in args, read with read.
+func (c *importCmd) loadManifest(args []string, read func(path string) ([]importItem, error)) (*importManifest, error) {
+	if c.resume != "" {
+		if len(args) != 0 {
+			return nil, errors.New("--resume takes no FILE, the manifest lists the items")
+		}
+
+		manifest := &importManifest{path: c.resume}
+		// readJSONFile is fine with a missing file, resuming isn't.
+		if _, err := os.Stat(c.resume); err != nil {
+			return nil, err
+		}
+		if err := readJSONFile(c.resume, manifest); err != nil {
+			return nil, err
+		}
+
+		return manifest, nil
+	}
+
+	if len(args) != 1 {
+		return nil, errors.New("expected exactly one FILE")
+	}
+
+	items, err := read(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	path := c.manifest
+	if path == "" {
+		path = args[0] + ".manifest.json"
+	}
+
+	manifest := &importManifest{Source: args[0], Items: items, path: path}
+	if err := manifest.write(); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func readImportFile(path string) ([]importItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var items []importItem
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		items = append(items, importItem{
+			Params: readwisereader.SaveParams{URL: line},
+			Status: importPending,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	return items, nil
+}
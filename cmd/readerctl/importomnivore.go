@@ -0,0 +1,243 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// omnivoreSavedUsing is the saved_using of documents imported from Omnivore.
+const omnivoreSavedUsing = "omnivore"
+
+// omnivoreItem is an entry of the metadata_*.json files of an Omnivore
+// export.
+type omnivoreItem struct {
+	Slug        string          `json:"slug"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Author      string          `json:"author"`
+	URL         string          `json:"url"`
+	State       string          `json:"state"`
+	Thumbnail   string          `json:"thumbnail"`
+	Labels      []omnivoreLabel `json:"labels"`
+	PublishedAt string          `json:"publishedAt"`
+}
+
+// omnivoreLabel is the name of a label, which some exports list as objects
+// with a name and a color.
+type omnivoreLabel string
+
+func (l *omnivoreLabel) UnmarshalJSON(b []byte) error {
+	var label struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(b, &label); err == nil {
+		*l = omnivoreLabel(label.Name)
+		return nil
+	}
+
+	return json.Unmarshal(b, (*string)(l))
+}
+
+type importOmnivoreCmd struct {
+	*importCmd
+	workers int
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newImportOmnivoreCmd(parent *importCmd) *importOmnivoreCmd {
+	cmd := &importOmnivoreCmd{importCmd: parent}
+	cmd.flags = ff.NewFlagSet("omnivore").SetParent(parent.flags)
+	cmd.flags.IntVar(&cmd.workers, 0, "workers", 8, "number of concurrent checks of whether pages still resolve")
+	cmd.command = &ff.Command{
+		Name:      "omnivore",
+		Usage:     "readerctl import omnivore [FLAGS] <DIR>",
+		ShortHelp: "save the items of an Omnivore export",
+		LongHelp: `DIR is an Omnivore export, unzipped or the zip file itself, with its
+metadata_*.json files and the archived pages in content. Every item is saved
+with its labels as tags, archived ones to the archive and the rest to later.
+Deleted items are left out.
+
+The URL of every item with an archived page is checked first, and pages that
+no longer resolve are saved with the archived content instead of being
+fetched by Reader.
+
+Progress is recorded in a manifest like readerctl import does, resume an
+import with readerctl import --resume MANIFEST.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *importOmnivoreCmd) exec(ctx context.Context, args []string) error {
+	if len(args) == 1 {
+		// Keep the manifest next to the directory rather than in it.
+		args[0] = filepath.Clean(args[0])
+	}
+
+	return c.importFile(ctx, args, func(export string) ([]importItem, error) {
+		return c.readOmnivoreExport(ctx, export)
+	})
+}
+
+// readOmnivoreExport reads the items of the export, checking which pages are
+// gone to save their archived content.
+func (c *importOmnivoreCmd) readOmnivoreExport(ctx context.Context, export string) ([]importItem, error) {
+	fsys, closeExport, err := openOmnivoreExport(export)
+	if err != nil {
+		return nil, err
+	}
+	defer closeExport()
+
+	var metadata []string
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if base := path.Base(name); !d.IsDir() && strings.HasPrefix(base, "metadata_") && strings.HasSuffix(base, ".json") {
+			metadata = append(metadata, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", export, err)
+	}
+	if len(metadata) == 0 {
+		return nil, fmt.Errorf("no metadata_*.json in %s, not an Omnivore export", export)
+	}
+
+	var items []importItem
+	// Archived pages by the URL of their item.
+	content := map[string]string{}
+	for _, name := range metadata {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var entries []omnivoreItem
+		if err := json.Unmarshal(b, &entries); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", name, err)
+		}
+
+		for _, entry := range entries {
+			if entry.URL == "" || strings.EqualFold(entry.State, "deleted") {
+				continue
+			}
+
+			items = append(items, omnivoreImportItem(entry))
+			if entry.Slug != "" {
+				content[entry.URL] = path.Join(path.Dir(name), "content", entry.Slug+".html")
+			}
+		}
+	}
+
+	// Only pages with an archived copy are worth checking.
+	docs := func(yield func(readwisereader.Document, error) bool) {
+		for u, name := range content {
+			if _, err := fs.Stat(fsys, name); err != nil {
+				continue
+			}
+			if !yield(readwisereader.Document{SourceURL: u}, nil) {
+				return
+			}
+		}
+	}
+
+	checker := readwisereader.LinkChecker{Workers: c.workers}
+	gone := map[string]bool{}
+	for result, err := range checker.Check(ctx, docs) {
+		if err != nil {
+			return nil, err
+		}
+		if result.Dead {
+			gone[result.Document.SourceURL] = true
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		params := &items[i].Params
+		if !gone[params.URL] {
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, content[params.URL])
+		if err != nil {
+			return nil, err
+		}
+		params.HTML = optional(string(b))
+	}
+
+	if len(gone) > 0 {
+		fmt.Fprintf(c.stderr, "%d pages no longer resolve, saving their archived content\n", len(gone))
+	}
+
+	return items, nil
+}
+
+// openOmnivoreExport opens the export directory, or the zip file.
+func openOmnivoreExport(export string) (fs.FS, func() error, error) {
+	fi, err := os.Stat(export)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fi.IsDir() {
+		return os.DirFS(export), func() error { return nil }, nil
+	}
+
+	zr, err := zip.OpenReader(export)
+	if errors.Is(err, zip.ErrFormat) {
+		return nil, nil, fmt.Errorf("%s is neither a directory nor a zip file", export)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return zr, zr.Close, nil
+}
+
+func omnivoreImportItem(entry omnivoreItem) importItem {
+	params := readwisereader.SaveParams{
+		URL:        entry.URL,
+		Title:      optional(entry.Title),
+		Author:     optional(entry.Author),
+		Summary:    optional(entry.Description),
+		ImageURL:   optional(entry.Thumbnail),
+		Location:   readwisereader.LocationLater,
+		SavedUsing: optional(omnivoreSavedUsing),
+	}
+	if strings.EqualFold(entry.State, "archived") {
+		params.Location = readwisereader.LocationArchive
+	}
+
+	if t, err := time.Parse(time.RFC3339, entry.PublishedAt); err == nil {
+		params.PublishedDate = &t
+	}
+
+	for _, label := range entry.Labels {
+		if tag := strings.TrimSpace(string(label)); tag != "" {
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+
+	return importItem{Params: params, Status: importPending}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// pocketSavedUsing is the saved_using of documents imported from Pocket.
+const pocketSavedUsing = "pocket"
+
+type importPocketCmd struct {
+	*importCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newImportPocketCmd(parent *importCmd) *importPocketCmd {
+	cmd := &importPocketCmd{importCmd: parent}
+	cmd.flags = ff.NewFlagSet("pocket").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "pocket",
+		Usage:     "readerctl import pocket [FLAGS] <FILE>",
+		ShortHelp: "save the items of a Pocket export",
+		LongHelp: `FILE is a Pocket export, either the ril_export.html of the older
+exports or a part_*.csv of the newer ones. Every item is saved with its tags,
+unread ones to later and read ones to the archive. Reader doesn't take the
+time an item was saved, so the time it was added to Pocket becomes its
+published date.
+
+Progress is recorded in a manifest like readerctl import does, resume an
+import with readerctl import --resume MANIFEST.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *importPocketCmd) exec(ctx context.Context, args []string) error {
+	return c.importFile(ctx, args, readPocketExport)
+}
+
+// readPocketExport reads the items of a Pocket export, telling the HTML
+// export from the CSV one by its first byte.
+func readPocketExport(path string) ([]importItem, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimPrefix(b, []byte("\ufeff"))
+
+	var items []importItem
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("<")) {
+		items, err = readPocketHTML(bytes.NewReader(b))
+	} else {
+		items, err = readPocketCSV(bytes.NewReader(b))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	return items, nil
+}
+
+// readPocketHTML reads the links of ril_export.html, listed under an
+// "Unread" and a "Read Archive" heading.
+func readPocketHTML(r io.Reader) ([]importItem, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []importItem
+	var archived bool
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+
+		switch n.DataAtom {
+		case atom.H1:
+			archived = strings.Contains(strings.ToLower(nodeText(n)), "archive")
+		case atom.A:
+			var u, added, tags string
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "href":
+					u = attr.Val
+				case "time_added":
+					added = attr.Val
+				case "tags":
+					tags = attr.Val
+				}
+			}
+			if u == "" {
+				continue
+			}
+
+			items = append(items, pocketItem(u, strings.TrimSpace(nodeText(n)), added, strings.Split(tags, ","), archived))
+		}
+	}
+
+	return items, nil
+}
+
+// readPocketCSV reads a CSV export, with title, url, time_added, tags and
+// status columns and tags separated by |.
+func readPocketCSV(r io.Reader) ([]importItem, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("no url column, not a Pocket export")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var items []importItem
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		u := field(record, "url")
+		if u == "" {
+			continue
+		}
+
+		archived := field(record, "status") == "archive"
+		items = append(items, pocketItem(u, field(record, "title"), field(record, "time_added"), strings.Split(field(record, "tags"), "|"), archived))
+	}
+
+	return items, nil
+}
+
+// pocketItem is the item saving a Pocket link, added is the Unix time it was
+// saved to Pocket.
+func pocketItem(u, title, added string, tags []string, archived bool) importItem {
+	params := readwisereader.SaveParams{
+		URL:        u,
+		Location:   readwisereader.LocationLater,
+		SavedUsing: optional(pocketSavedUsing),
+	}
+	if archived {
+		params.Location = readwisereader.LocationArchive
+	}
+
+	// Pocket uses the URL as the title of items it couldn't get one for.
+	if title != u {
+		params.Title = optional(title)
+	}
+
+	if sec, err := strconv.ParseInt(added, 10, 64); err == nil && sec > 0 {
+		t := time.Unix(sec, 0).UTC()
+		params.PublishedDate = &t
+	}
+
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+
+	return importItem{Params: params, Status: importPending}
+}
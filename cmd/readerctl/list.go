@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type listCmd struct {
+	*rootCmd
+	maxMinutes int
+	withHTML   bool
+	htmlDir    string
+	id         string
+	location   string
+	category   string
+	format     string
+
+	updatedAfter timeValue
+	savedAfter   timeValue
+	savedBefore  timeValue
+
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newListCmd(root *rootCmd) *listCmd {
+	cmd := &listCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("list").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.id, 0, "id", "", "only list the document with this ID")
+	cmd.flags.StringVar(&cmd.location, 0, "location", "", "only list documents in these locations, comma separated")
+	cmd.flags.StringVar(&cmd.category, 0, "category", "", "only list documents of these categories, comma separated")
+	cmd.flags.StringVar(&cmd.format, 0, "format", "", formatFlagHelp)
+	cmd.flags.IntVar(&cmd.maxMinutes, 0, "max-minutes", 0, "only list documents readable within this many minutes")
+	cmd.flags.BoolVar(&cmd.withHTML, 0, "with-html", "include the html content of documents")
+	cmd.flags.StringVar(&cmd.htmlDir, 0, "html-dir", "", "write the html content of each document to a file in this directory, implies --with-html")
+	cmd.flags.Value(0, "updated-after", &cmd.updatedAfter, "only list documents updated after this time, e.g. 36h, monday or 2024-05-01")
+	cmd.flags.Value(0, "saved-after", &cmd.savedAfter, "only list documents saved after this time")
+	cmd.flags.Value(0, "saved-before", &cmd.savedBefore, "only list documents saved before this time")
+	cmd.command = &ff.Command{
+		Name:      "list",
+		Usage:     "readerctl list [FLAGS]",
+		ShortHelp: "list documents",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *listCmd) exec(ctx context.Context, args []string) error {
+	if !isTabular(c.output) && c.output != outputJSON && c.output != outputJSONL {
+		return unsupportedOutput(c.output)
+	}
+
+	locations, err := parseLocations(c.location)
+	if err != nil {
+		return &usageError{err: err}
+	}
+
+	categories, err := parseCategories(c.category)
+	if err != nil {
+		return &usageError{err: err}
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	if c.htmlDir != "" {
+		if err := os.MkdirAll(c.htmlDir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	script, err := c.loadScript(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	format, err := parseFormat(c.format)
+	if err != nil {
+		return err
+	}
+	if format != nil && script.has("transform") {
+		return &usageError{err: errors.New("--format and a transform script both render documents, use one")}
+	}
+
+	params := readwisereader.ListParams{
+		ID:              c.id,
+		UpdatedAfter:    c.updatedAfter.Time,
+		WithHTMLContent: c.withHTML || c.htmlDir != "",
+	}
+
+	// Documents streamed to the terminal show how far the listing got, and
+	// would land on the progress line.
+	progress := c.listProgress("listing")
+	if isTerminal(c.stdout) && (format != nil || c.output == outputJSONL && !script.has("transform")) {
+		progress = nil
+	}
+	progress.track(&params)
+	defer progress.clear()
+
+	// The API filters by a single location and category, more take a
+	// listing per combination.
+	var listing iter.Seq2[readwisereader.Document, error]
+	if len(locations) > 1 || len(categories) > 1 {
+		params.Locations, params.Categories = locations, categories
+		listing = progress.counted(client.ListFanOut(ctx, params))
+	} else {
+		if len(locations) == 1 {
+			params.Location = locations[0]
+		}
+		if len(categories) == 1 {
+			params.Category = categories[0]
+		}
+		listing = progress.documents(client.ListPaginate(ctx, params))
+	}
+
+	var docs []readwisereader.Document
+	for doc, err := range listing {
+		if err != nil {
+			return err
+		}
+
+		if !fitsMinutes(readingTime(doc.WordCount, c.wpm), c.maxMinutes) {
+			continue
+		}
+
+		if !c.savedAfter.IsZero() && !doc.SavedAt.After(c.savedAfter.Time) {
+			continue
+		}
+
+		if !c.savedBefore.IsZero() && !doc.SavedAt.Before(c.savedBefore.Time) {
+			continue
+		}
+
+		keep, err := script.filter(doc)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			continue
+		}
+
+		if err := c.writeHTML(doc); err != nil {
+			return err
+		}
+
+		if format != nil {
+			if err := writeFormatted(c.stdout, format, doc); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Stream documents as their page arrives, unless a transform
+		// takes over the output.
+		if c.output == outputJSONL && !script.has("transform") {
+			if err := writeJSONLine(c.stdout, doc); err != nil {
+				return err
+			}
+			continue
+		}
+
+		docs = append(docs, doc)
+	}
+	progress.clear()
+
+	if script.has("transform") {
+		for _, doc := range docs {
+			line, err := script.transform(doc)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(c.stdout, line)
+		}
+		return nil
+	}
+
+	// Streamed documents are not collected, only the rest are left to write.
+	return c.writeDocuments(docs, format)
+}
+
+// writeDocuments writes docs in the --output format, or with a --format
+// template if there is one.
+func (r *rootCmd) writeDocuments(docs []readwisereader.Document, format *template.Template) error {
+	switch {
+	case format != nil:
+		for _, doc := range docs {
+			if err := writeFormatted(r.stdout, format, doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	case r.output == outputJSONL:
+		for _, doc := range docs {
+			if err := writeJSONLine(r.stdout, doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	case r.output == outputJSON:
+		return writeJSON(r.stdout, docs)
+	}
+
+	t := newTable("ID", "TITLE", "READING TIME")
+	for _, doc := range docs {
+		t.add(doc.ID, doc.Title, formatReadingTime(readingTime(doc.WordCount, r.wpm)))
+	}
+
+	return r.writeTable(t)
+}
+
+// writeHTML writes the html content of doc to the --html-dir, if any.
+// Documents without content, highlights and notes included, are skipped.
+func (c *listCmd) writeHTML(doc readwisereader.Document) error {
+	if c.htmlDir == "" || doc.HTMLContent == "" {
+		return nil
+	}
+
+	path := filepath.Join(c.htmlDir, documentFileName(doc.ID, doc.Title, ".html"))
+	return os.WriteFile(path, []byte(doc.HTMLContent), 0o644)
+}
+
+// fitsMinutes reports whether a reading time estimate is within max minutes.
+// Documents without an estimate never fit, as there is no telling how long
+// they take.
+func fitsMinutes(rt time.Duration, max int) bool {
+	if max <= 0 {
+		return true
+	}
+
+	return rt > 0 && rt <= time.Duration(max)*time.Minute
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	gosync "sync"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// listProgress shows how far a listing got on a single stderr line, against
+// the count the API reports with the first page when there is one. It shows
+// nothing with --quiet or when stderr isn't a terminal.
+type listProgress struct {
+	mu    gosync.Mutex
+	w     io.Writer
+	verb  string
+	total int
+	pages int
+	docs  int
+	// wait is how long a rate limited page is waited out for, until the
+	// next one comes in.
+	wait time.Duration
+	// shown is whether the progress line is on stderr.
+	shown bool
+}
+
+func (r *rootCmd) listProgress(verb string) *listProgress {
+	if r.quiet || !isTerminal(r.stderr) {
+		return nil
+	}
+
+	return &listProgress{w: r.stderr, verb: verb}
+}
+
+// track makes params report rate limit waits to p.
+func (p *listProgress) track(params *readwisereader.ListParams) {
+	if p != nil {
+		params.OnRateLimited = p.rateLimited
+	}
+}
+
+// page counts a page of a listing, its count standing for the total of the
+// first one.
+func (p *listProgress) page(page readwisereader.Page) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pages == 0 {
+		p.total = page.Count
+	}
+	p.pages++
+	p.docs += len(page.Results)
+	p.wait = 0
+	p.print()
+}
+
+// doc counts a document of a listing that yields no pages, which has no
+// total to show.
+func (p *listProgress) doc() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.docs++
+	p.wait = 0
+	p.print()
+}
+
+// documents yields the documents of pages, counting each page in p.
+func (p *listProgress) documents(pages iter.Seq2[readwisereader.Page, error]) iter.Seq2[readwisereader.Document, error] {
+	return func(yield func(readwisereader.Document, error) bool) {
+		for page, err := range pages {
+			if err != nil {
+				yield(readwisereader.Document{}, err)
+				return
+			}
+
+			p.page(page)
+			for _, doc := range page.Results {
+				if !yield(doc, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// counted yields docs, counting each one in p.
+func (p *listProgress) counted(docs iter.Seq2[readwisereader.Document, error]) iter.Seq2[readwisereader.Document, error] {
+	return func(yield func(readwisereader.Document, error) bool) {
+		for doc, err := range docs {
+			if err == nil {
+				p.doc()
+			}
+			if !yield(doc, err) {
+				return
+			}
+		}
+	}
+}
+
+func (p *listProgress) rateLimited(wait time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.wait = wait
+	p.print()
+}
+
+// clear removes the progress line, before anything else is printed to
+// stderr and once the listing is done.
+func (p *listProgress) clear() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.shown {
+		fmt.Fprint(p.w, "\r\x1b[K")
+		p.shown = false
+	}
+}
+
+func (p *listProgress) print() {
+	fmt.Fprint(p.w, "\r\x1b[K")
+	p.shown = true
+
+	switch {
+	case p.total > 0:
+		fmt.Fprintf(p.w, "%s %s %d/%d documents, %d pages", p.verb, progressBar(float64(p.docs)/float64(p.total), 20), p.docs, p.total, p.pages)
+	case p.pages > 0:
+		fmt.Fprintf(p.w, "%s %d documents, %d pages", p.verb, p.docs, p.pages)
+	default:
+		fmt.Fprintf(p.w, "%s %d documents", p.verb, p.docs)
+	}
+
+	if p.wait > 0 {
+		fmt.Fprintf(p.w, ", rate limited, waiting %s", p.wait)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	"github.com/peterbourgon/ff/v4"
+	"github.com/peterbourgon/ff/v4/ffhelp"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	cancel()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	root := newRootCmd(stdin, stdout, stderr)
+	registerCommands(root)
+	root.tokenFlag = hasFlag(args, "token")
+
+	err := root.command.Parse(args,
+		ff.WithEnvVarPrefix("READERCTL"),
+		ff.WithConfigFileFlag("config"),
+		ff.WithConfigFileParser(root.parseConfig),
+		ff.WithConfigAllowMissingFile(),
+		ff.WithConfigIgnoreUndefinedFlags(),
+	)
+	if err != nil && !errors.Is(err, ff.ErrHelp) {
+		// A broken config file must not keep config validate from telling
+		// what is wrong with it.
+		retry := newRootCmd(stdin, stdout, stderr)
+		registerCommands(retry)
+		if retry.command.Parse(args, ff.WithEnvVarPrefix("READERCTL")) == nil && retry.command.GetSelected().Name == "validate" {
+			root, err = retry, nil
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ff.ErrHelp) {
+			fmt.Fprintf(stderr, "%s\n", ffhelp.Command(root.command.GetSelected()))
+			return 0
+		}
+		// Keep stderr machine readable when errors are reported as JSON.
+		if root.output != outputJSON {
+			fmt.Fprintf(stderr, "%s\n", ffhelp.Command(root.command.GetSelected()))
+		}
+		return root.reportError(&usageError{err: err})
+	}
+
+	if err := root.useProfile(); err != nil {
+		return root.reportError(&usageError{err: err})
+	}
+
+	// ndjson is another name for jsonl.
+	if root.output == outputNDJSON {
+		root.output = outputJSONL
+	}
+
+	if err := root.command.Run(ctx); err != nil {
+		if errors.Is(err, ff.ErrNoExec) {
+			fmt.Fprintf(stderr, "%s\n", ffhelp.Command(root.command.GetSelected()))
+			return 0
+		}
+		return root.reportError(err)
+	}
+
+	return 0
+}
+
+func registerCommands(root *rootCmd) {
+	newListCmd(root)
+	newSyncCmd(root)
+	newStatsCmd(root)
+	newGoalsCmd(root)
+	newUnreadCmd(root)
+	newFeedsCmd(root)
+	newTagsCmd(root)
+	newTagCmd(root)
+	newBoardCmd(root)
+	newTUICmd(root)
+	newShareCmd(root)
+	newOpenCmd(root)
+	newHighlightCmd(root)
+	newExistsCmd(root)
+	newProgressCmd(root)
+	newGetCmd(root)
+	newMoveCmd(root)
+	newDeleteCmd(root)
+	newMoveToCmd(root, "archive", readwisereader.LocationArchive)
+	newMoveToCmd(root, "later", readwisereader.LocationLater)
+	newMoveToCmd(root, "shortlist", readwisereader.LocationShortList)
+	newSnoozeCmd(root)
+	newDescribeCmd(root)
+	newReadCmd(root)
+	newCheckLinksCmd(root)
+	newSaveCmd(root)
+	newQueueCmd(root)
+	newImportCmd(root)
+	newExportCmd(root)
+	newDaemonCmd(root)
+	newGrepCmd(root)
+	newSearchCmd(root)
+	newSendCmd(root)
+	newSelfupdateCmd(root)
+	newCompleteCmd(root)
+	newCompletionCmd(root)
+	newConfigCmd(root)
+	newAuthCmd(root)
+}
+
+type rootCmd struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	token    string
+	config   string
+	profile  string
+	cacheDir string
+	cacheTTL time.Duration
+	wpm      int
+	output   string
+	table    tableOptions
+	extract  bool
+	warnings bool
+	script   string
+	limit    bool
+	debug    bool
+	quiet    bool
+
+	// profileFound is whether the config file has a header for profile.
+	profileFound bool
+	// tokenFlag is whether the token was given with --token.
+	tokenFlag bool
+
+	// fileStore is shared by every command of the process, a second store
+	// on the same file would wait on its own lock.
+	fileStore *sync.FileStore
+
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newRootCmd(stdin io.Reader, stdout, stderr io.Writer) *rootCmd {
+	root := &rootCmd{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	configDir := defaultConfigDir()
+
+	root.flags = ff.NewFlagSet("readerctl")
+	root.flags.StringVar(&root.token, 0, "token", "", "Readwise access token, or several separated by commas to rotate through")
+	root.flags.StringVar(&root.config, 0, "config", filepath.Join(configDir, "config"), "config file")
+	root.flags.StringVar(&root.profile, 0, "profile", "", "profile of the config file to use, with its own token, defaults, cache and state")
+	root.flags.StringVar(&root.cacheDir, 0, "cache-dir", filepath.Join(configDir, "cache"), "directory for the local document cache")
+	root.flags.DurationVar(&root.cacheTTL, 0, "cache-ttl", 0, "reuse single page API responses for this long, 0 to disable")
+	root.flags.StringVar(&root.output, 'o', "output", outputTable, "output format: table, json, jsonl (or ndjson), csv or tsv, and prompt or quickfix where supported")
+	root.flags.IntVar(&root.table.maxWidth, 0, "max-width", 0, "maximum table width, 0 for unlimited")
+	root.flags.BoolVar(&root.table.truncate, 0, "truncate", "truncate table cells to fit the terminal")
+	root.flags.BoolVar(&root.table.wrap, 0, "wrap", "wrap table cells instead of truncating them")
+	root.flags.StringListVar(&root.table.columnWidths, 0, "column-width", "maximum width of a table column as NAME=WIDTH, repeatable")
+	root.flags.BoolVar(&root.extract, 0, "extract", "extract content locally from the source page for documents Reader has no content for")
+	root.flags.BoolVar(&root.warnings, 0, "decode-warnings", "report documents with malformed fields in API responses on stderr")
+	root.flags.BoolVar(&root.debug, 0, "debug", "log API requests and responses on stderr")
+	root.flags.BoolVar(&root.quiet, 'q', "quiet", "don't show progress on stderr")
+	root.flags.BoolVar(&root.limit, 0, "rate-limit", "pace requests to stay within the API rate limits instead of waiting them out")
+	root.flags.StringVar(&root.script, 0, "script", "", "Starlark script with filter, transform and on_event hooks")
+	root.flags.IntVar(&root.wpm, 0, "wpm", defaultWPM, "reading speed in words per minute, used for reading time estimates")
+
+	root.command = &ff.Command{
+		Name:      "readerctl",
+		Usage:     "readerctl [FLAGS] <SUBCOMMAND> ...",
+		ShortHelp: "interact with Readwise Reader",
+		Flags:     root.flags,
+	}
+
+	return root
+}
+
+func (r *rootCmd) addCommand(cmd *ff.Command) {
+	r.command.Subcommands = append(r.command.Subcommands, cmd)
+}
+
+var errNoToken = errors.New("no token configured, run readerctl auth login or set --token or READERCTL_TOKEN")
+
+func (r *rootCmd) client() (*readwisereader.Client, error) {
+	token, _, err := r.resolveToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.newClient(token)
+}
+
+// newClient returns a client using token, which may hold several separated
+// by commas.
+func (r *rootCmd) newClient(token string) (*readwisereader.Client, error) {
+	var tokens []string
+	for _, token := range strings.Split(token, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, errNoToken
+	}
+
+	opts := []readwisereader.Option{
+		readwisereader.WithTokens(tokens...),
+		readwisereader.WithUserAgent("readerctl/" + currentVersion()),
+	}
+	if r.limit {
+		opts = append(opts, readwisereader.WithRateLimit(readwisereader.DefaultRateLimits))
+	}
+	if r.debug {
+		logger := slog.New(slog.NewTextHandler(r.stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts = append(opts, readwisereader.WithLogger(logger))
+	}
+
+	client := readwisereader.NewClient(tokens[0], opts...)
+	if r.warnings {
+		client.SetDecodeWarningHandler(func(w readwisereader.DecodeWarning) {
+			fmt.Fprintf(r.stderr, "warning: %s\n", w)
+		})
+	}
+
+	if r.cacheTTL > 0 {
+		client.SetCache(readwisereader.NewDiskCache(filepath.Join(r.cacheDir, "api")), r.cacheTTL)
+	}
+
+	return client, nil
+}
+
+func (r *rootCmd) store() *sync.FileStore {
+	if r.fileStore == nil {
+		r.fileStore = sync.NewFileStore(filepath.Join(r.cacheDir, "documents.json"))
+	}
+
+	return r.fileStore
+}
+
+func defaultConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".readerctl"
+	}
+
+	return filepath.Join(dir, "readerctl")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	gosync "sync"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+)
+
+// daemonMetrics are the sync statistics the daemon exposes next to the
+// client's request counters.
+type daemonMetrics struct {
+	mu         gosync.Mutex
+	syncs      uint64
+	syncErrors uint64
+	documents  uint64
+	deleted    uint64
+	lastSync   time.Time
+}
+
+func (m *daemonMetrics) recordSync(result *sync.Result, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.syncs++
+	if err != nil {
+		m.syncErrors++
+		return
+	}
+
+	m.documents += uint64(result.Documents)
+	m.deleted += uint64(result.Deleted)
+	m.lastSync = time.Now()
+}
+
+// write writes the metrics in the Prometheus text exposition format.
+func (m *daemonMetrics) write(w io.Writer, stats readwisereader.Stats, rate readwisereader.RateLimitStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metric := func(name, kind, help string, value any) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+
+	metric("readerctl_api_requests_total", "counter", "Requests sent to the Reader API, retries included.", stats.Requests)
+	metric("readerctl_api_errors_total", "counter", "Requests to the Reader API that failed or got an error status.", stats.Errors)
+	metric("readerctl_api_rate_limited_total", "counter", "Requests to the Reader API that were rate limited.", stats.RateLimited)
+	if !rate.ObservedAt.IsZero() {
+		metric("readerctl_api_rate_limit_remaining", "gauge", "Requests left in the current rate limit window, as last reported by the API.", rate.Remaining)
+	}
+	metric("readerctl_syncs_total", "counter", "Syncs run, failed ones included.", m.syncs)
+	metric("readerctl_sync_errors_total", "counter", "Syncs that failed.", m.syncErrors)
+	metric("readerctl_synced_documents_total", "counter", "Documents fetched by syncs.", m.documents)
+	metric("readerctl_deleted_documents_total", "counter", "Cached documents dropped by reconciliation.", m.deleted)
+
+	// Leave the sync times out until there was a sync, a zero timestamp
+	// would look like a sync decades ago and fire alerts right away.
+	if !m.lastSync.IsZero() {
+		metric("readerctl_last_sync_timestamp_seconds", "gauge", "Time of the last successful sync.", m.lastSync.Unix())
+		metric("readerctl_last_sync_age_seconds", "gauge", "Seconds since the last successful sync.", int64(time.Since(m.lastSync).Seconds()))
+	}
+}
+
+// serveMetrics serves /metrics on addr until ctx is done.
+func (c *daemonCmd) serveMetrics(ctx context.Context, addr string, client *readwisereader.Client) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.metrics.write(w, client.Stats(), client.RateLimitStatus())
+	})
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(c.stderr, "metrics: %v\n", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	"code.selman.me/go-readwisereader/sync/sqlitestore"
+)
+
+// mirrorPath is the SQLite mirror readerctl sync --sqlite maintains.
+func (r *rootCmd) mirrorPath() string {
+	return filepath.Join(r.cacheDir, "documents.db")
+}
+
+// mirror opens the SQLite mirror, which has to exist unless create is set.
+func (r *rootCmd) mirror(create bool) (*sqlitestore.Store, error) {
+	path := r.mirrorPath()
+	if !create {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil, errors.New("no local mirror, run readerctl sync --sqlite first")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	return sqlitestore.Open(path)
+}
+
+// mirroredStore is the document cache, also writing what is synced into it
+// to the SQLite mirror so that a single sync keeps both up to date. History,
+// content hashes and every read are the cache's.
+type mirroredStore struct {
+	*sync.FileStore
+	mirror *sqlitestore.Store
+	// The mirror's sync state is kept under the config dir.
+	mirrorState jsonStateStore
+	// keepHTML is whether the cache keeps html content, which otherwise
+	// only goes to the mirror.
+	keepHTML bool
+}
+
+// openMirrored opens the SQLite mirror along with the cache. The mirror's
+// sync state is reset when the mirror itself is gone.
+func (r *rootCmd) openMirrored(ctx context.Context, keepHTML bool) (*mirroredStore, error) {
+	state := jsonStateStore(r.stateFile("mirror-state.json"))
+	if _, err := os.Stat(r.mirrorPath()); errors.Is(err, fs.ErrNotExist) {
+		if err := state.SaveState(ctx, sync.State{}); err != nil {
+			return nil, err
+		}
+	}
+
+	mirror, err := r.mirror(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mirroredStore{
+		FileStore:   r.store(),
+		mirror:      mirror,
+		mirrorState: state,
+		keepHTML:    keepHTML,
+	}, nil
+}
+
+func (s *mirroredStore) Close() error {
+	return s.mirror.Close()
+}
+
+// State is the state of whichever of the two is further behind, so that a
+// sync brings the mirror up to date too when it was left out of earlier ones.
+func (s *mirroredStore) State(ctx context.Context) (sync.State, error) {
+	state, err := s.FileStore.State(ctx)
+	if err != nil {
+		return sync.State{}, err
+	}
+
+	mirrored, err := s.mirrorState.State(ctx)
+	if err != nil {
+		return sync.State{}, err
+	}
+
+	if mirrored.Cursor != state.Cursor || !mirrored.CursorStartedAt.Equal(state.CursorStartedAt) {
+		// Only an interrupted sync of both can be resumed.
+		state.Cursor, state.CursorStartedAt = "", time.Time{}
+	}
+	if mirrored.LastSyncAt.Before(state.LastSyncAt) {
+		state.LastSyncAt = mirrored.LastSyncAt
+	}
+	if mirrored.LastReconcileAt.Before(state.LastReconcileAt) {
+		state.LastReconcileAt = mirrored.LastReconcileAt
+	}
+
+	return state, nil
+}
+
+func (s *mirroredStore) SaveState(ctx context.Context, state sync.State) error {
+	if err := s.mirrorState.SaveState(ctx, state); err != nil {
+		return err
+	}
+
+	return s.FileStore.SaveState(ctx, state)
+}
+
+func (s *mirroredStore) Put(ctx context.Context, docs []readwisereader.Document) error {
+	if err := s.mirror.Put(ctx, docs); err != nil {
+		return fmt.Errorf("mirror: %w", err)
+	}
+
+	if !s.keepHTML {
+		docs = slices.Clone(docs)
+		for i := range docs {
+			docs[i].HTMLContent = ""
+		}
+	}
+
+	return s.FileStore.Put(ctx, docs)
+}
+
+func (s *mirroredStore) Delete(ctx context.Context, ids []string) error {
+	if err := s.mirror.Delete(ctx, ids); err != nil {
+		return fmt.Errorf("mirror: %w", err)
+	}
+
+	return s.FileStore.Delete(ctx, ids)
+}
+
+// jsonStateStore is a sync.StateStore kept in a JSON file.
+type jsonStateStore string
+
+func (path jsonStateStore) State(ctx context.Context) (sync.State, error) {
+	var state sync.State
+	err := readJSONFile(string(path), &state)
+	return state, err
+}
+
+func (path jsonStateStore) SaveState(ctx context.Context, state sync.State) error {
+	return writeJSONFile(string(path), state)
+}
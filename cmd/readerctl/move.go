@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type moveCmd struct {
+	*rootCmd
+	to       string
+	location readwisereader.Location
+	flags    *ff.FlagSet
+	command  *ff.Command
+}
+
+func newMoveCmd(root *rootCmd) *moveCmd {
+	cmd := &moveCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("move").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.to, 0, "to", "", "location to move the documents to: new, later, shortlist, archive or feed")
+	cmd.command = &ff.Command{
+		Name:      "move",
+		Usage:     "readerctl move [FLAGS] --to <LOCATION> [<ID>...]",
+		ShortHelp: "move documents to another location",
+		LongHelp:  "Without IDs, or with -, IDs are read from stdin, one per line.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+// newMoveToCmd adds a shortcut for moving documents to location.
+func newMoveToCmd(root *rootCmd, name string, location readwisereader.Location) *moveCmd {
+	cmd := &moveCmd{rootCmd: root, location: location}
+	cmd.flags = ff.NewFlagSet(name).SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      name,
+		Usage:     fmt.Sprintf("readerctl %s [FLAGS] [<ID>...]", name),
+		ShortHelp: fmt.Sprintf("move documents to %s, same as move --to %s", location, location),
+		LongHelp:  "Without IDs, or with -, IDs are read from stdin, one per line.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type moveResult struct {
+	ID       string                  `json:"id"`
+	Location readwisereader.Location `json:"location"`
+}
+
+func (c *moveCmd) exec(ctx context.Context, args []string) error {
+	if c.output != outputTable && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	location := c.location
+	if location == "" {
+		if c.to == "" {
+			return &usageError{err: errors.New("--to is required")}
+		}
+
+		var err error
+		location, err = parseLocation(c.to)
+		if err != nil {
+			return &usageError{err: err}
+		}
+	}
+
+	ids, err := documentIDs(args, c.stdin)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var results []moveResult
+	for _, id := range ids {
+		if _, err := client.Update(ctx, id, readwisereader.UpdateParams{Location: location}); err != nil {
+			return fmt.Errorf("move %s: %w", id, err)
+		}
+
+		results = append(results, moveResult{ID: id, Location: location})
+		if c.output == outputTable {
+			fmt.Fprintln(c.stdout, id)
+		}
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, results)
+	}
+
+	fmt.Fprintf(c.stderr, "moved %d documents to %s\n", len(results), location)
+	return nil
+}
+
+// documentIDs returns the document IDs given as arguments, read from r, one
+// per line, when there are none or the only one is -. Only the first field of
+// each line is taken, so tabular output can be piped in as is.
+func documentIDs(args []string, r io.Reader) ([]string, error) {
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		return args, nil
+	}
+
+	var ids []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if fields := strings.Fields(s.Text()); len(fields) > 0 {
+			ids = append(ids, fields[0])
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read IDs: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil, &usageError{err: errors.New("no document IDs given")}
+	}
+
+	return ids, nil
+}
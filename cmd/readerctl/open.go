@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+type openCmd struct {
+	*rootCmd
+	reader  bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newOpenCmd(root *rootCmd) *openCmd {
+	cmd := &openCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("open").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.reader, 0, "reader", "open the documents in Reader rather than their source")
+	cmd.command = &ff.Command{
+		Name:      "open",
+		Usage:     "readerctl open [FLAGS] <ID>...",
+		ShortHelp: "open documents in the browser",
+		LongHelp:  "Documents are looked up in the local cache first, and fetched when missing.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *openCmd) exec(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected at least one document ID")
+	}
+
+	for _, id := range args {
+		doc, err := c.lookupDocument(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		u := documentLink(*doc)
+		if c.reader {
+			u = doc.URL
+		}
+
+		if err := openBrowser(u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
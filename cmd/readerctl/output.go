@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputJSONL    = "jsonl"
+	outputNDJSON   = "ndjson"
+	outputCSV      = "csv"
+	outputTSV      = "tsv"
+	outputPrompt   = "prompt"
+	outputQuickfix = "quickfix"
+)
+
+// knownOutputs are the values --output takes, not every command supports all
+// of them.
+var knownOutputs = []string{outputTable, outputJSON, outputJSONL, outputNDJSON, outputCSV, outputTSV, outputPrompt, outputQuickfix}
+
+// isTabular reports whether output is written by writeTable, as an aligned
+// table or delimited rows.
+func isTabular(output string) bool {
+	return output == outputTable || output == outputCSV || output == outputTSV
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeJSONLine writes v as a single line of JSON, flushing w if it buffers so
+// that consumers at the other end of a pipe see it right away.
+func writeJSONLine(w io.Writer, v any) error {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return err
+	}
+
+	if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// formatEscapes expands the escapes --format templates are usually written
+// with, shells make literal tabs and newlines awkward to type.
+var formatEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+var formatFuncs = template.FuncMap{
+	"join": func(sep string, elems []string) string {
+		return strings.Join(elems, sep)
+	},
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// formatFlagHelp is the help of the --format flag of commands that print
+// documents.
+const formatFlagHelp = "render each document with this Go template instead, e.g. '{{.ID}}\\t{{.Title}}'"
+
+// parseFormat parses a --format template, nil when there is none.
+func parseFormat(format string) (*template.Template, error) {
+	if format == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(formatEscapes.Replace(format))
+	if err != nil {
+		return nil, &usageError{err: fmt.Errorf("--format: %w", err)}
+	}
+
+	return tmpl, nil
+}
+
+// writeFormatted renders v with a --format template, one line each.
+func writeFormatted(w io.Writer, tmpl *template.Template, v any) error {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, v); err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func unsupportedOutput(output string) error {
+	return fmt.Errorf("unsupported output format: %q", output)
+}
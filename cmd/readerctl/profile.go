@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+// parseConfig parses the config file in ff's plain format, with [NAME]
+// headers starting the lines of a profile, which only apply with --profile
+// NAME. Lines before the first header apply to every profile, and may set
+// profile to pick one when there is no --profile.
+func (r *rootCmd) parseConfig(rd io.Reader, set func(name, value string) error) error {
+	var section string
+	s := bufio.NewScanner(rd)
+	for s.Scan() {
+		if name, ok := configSection(s.Text()); ok {
+			section = name
+			r.profileFound = r.profileFound || name == r.profile
+			continue
+		}
+
+		name, value, ok := parseConfigLine(s.Text())
+		if !ok || section != "" && section != r.profile {
+			continue
+		}
+
+		if err := set(name, value); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// configSection reports the profile a [NAME] header line of the config file
+// starts.
+func configSection(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if len(line) < 2 || line[0] != '[' || line[len(line)-1] != ']' {
+		return "", false
+	}
+
+	return strings.TrimSpace(line[1 : len(line)-1]), true
+}
+
+// configProfiles returns the profiles the config file at path has headers
+// for.
+func configProfiles(path string) []string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var profiles []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if name, ok := configSection(line); ok && !slices.Contains(profiles, name) {
+			profiles = append(profiles, name)
+		}
+	}
+
+	return profiles
+}
+
+// configSectionRange returns the lines of the config file setting values for
+// profile, all of them before the first header without one, or -1 if the
+// profile has no header.
+func configSectionRange(lines []string, profile string) (start, end int) {
+	start = -1
+	if profile == "" {
+		start = 0
+	}
+
+	for i, line := range lines {
+		name, ok := configSection(line)
+		switch {
+		case !ok:
+		case start >= 0:
+			return start, i
+		case name == profile:
+			start = i + 1
+		}
+	}
+
+	if start < 0 {
+		return -1, -1
+	}
+
+	return start, len(lines)
+}
+
+// useProfile keeps the cache of a profile apart from the others, unless
+// --cache-dir says where it goes. Only config commands, which set profiles
+// up, may use a profile the config file has no header for.
+func (r *rootCmd) useProfile() error {
+	if r.profile == "" {
+		return nil
+	}
+
+	if strings.ContainsAny(r.profile, `/\`) || r.profile == "." || r.profile == ".." {
+		return fmt.Errorf("invalid profile name %q", r.profile)
+	}
+
+	if !r.profileFound && !isConfigCommand(r.command.GetSelected()) {
+		return fmt.Errorf("no profile %q in %s", r.profile, r.config)
+	}
+
+	if f, ok := r.flags.GetFlag("cache-dir"); ok && !f.IsSet() {
+		r.cacheDir = filepath.Join(r.cacheDir, "profiles", r.profile)
+	}
+
+	return nil
+}
+
+func isConfigCommand(cmd *ff.Command) bool {
+	for ; cmd != nil; cmd = cmd.GetParent() {
+		if cmd.Name == "config" && cmd.GetParent() != nil && cmd.GetParent().GetParent() == nil {
+			return true
+		}
+	}
+
+	return false
+}
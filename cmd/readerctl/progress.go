@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type progressCmd struct {
+	*rootCmd
+	get     bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newProgressCmd(root *rootCmd) *progressCmd {
+	cmd := &progressCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("progress").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.get, 0, "get", "print the current reading progress instead of setting it")
+	cmd.command = &ff.Command{
+		Name:      "progress",
+		Usage:     "readerctl progress [FLAGS] <ID> [<PERCENT>]",
+		ShortHelp: "get or set the reading progress of a document",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *progressCmd) exec(ctx context.Context, args []string) error {
+	if c.get {
+		if len(args) != 1 {
+			return errors.New("expected exactly one document ID")
+		}
+
+		doc, err := c.fetchDocument(ctx, args[0], false)
+		if err != nil {
+			return err
+		}
+
+		if c.output == outputJSON {
+			return writeJSON(c.stdout, map[string]any{
+				"id":               doc.ID,
+				"reading_progress": doc.ReadingProgress,
+			})
+		}
+
+		fmt.Fprintf(c.stdout, "%.0f%%\n", doc.ReadingProgress*100)
+		return nil
+	}
+
+	if len(args) != 2 {
+		return errors.New("expected a document ID and a percentage")
+	}
+
+	progress, err := parsePercent(args[1])
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Update(ctx, args[0], readwisereader.UpdateParams{
+		ReadingProgress: &progress,
+	})
+	return err
+}
+
+// parsePercent parses "42" or "42%" into 0.42.
+func parsePercent(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || v < 0 || v > 100 {
+		return 0, fmt.Errorf("invalid percentage: %q", s)
+	}
+
+	return v / 100, nil
+}
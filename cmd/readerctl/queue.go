@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"code.selman.me/go-readwisereader/sync"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type queueCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newQueueCmd(root *rootCmd) *queueCmd {
+	cmd := &queueCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("queue").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "queue",
+		Usage:     "readerctl queue <SUBCOMMAND> ...",
+		ShortHelp: "saves and updates stored locally until they can be sent",
+		LongHelp:  "Operations are queued with readerctl save --queue, the daemon flushes the queue on every sync.",
+		Flags:     cmd.flags,
+	}
+
+	newQueueStatusCmd(cmd)
+	newQueueFlushCmd(cmd)
+	newQueueRetryCmd(cmd)
+	newQueueDropCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+// queue returns the queue kept in the local store.
+func (c *queueCmd) queue() (*sync.Queue, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	return sync.NewQueue(client, c.store()), nil
+}
+
+type queueStatusCmd struct {
+	*queueCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newQueueStatusCmd(parent *queueCmd) *queueStatusCmd {
+	cmd := &queueStatusCmd{queueCmd: parent}
+	cmd.flags = ff.NewFlagSet("status").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "status",
+		Usage:     "readerctl queue status",
+		ShortHelp: "list queued operations",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *queueStatusCmd) exec(ctx context.Context, args []string) error {
+	if !isTabular(c.output) && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	ops, err := c.store().Operations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, ops)
+	}
+
+	t := newTable("ID", "KIND", "TARGET", "QUEUED", "ATTEMPTS", "STATUS")
+	for _, op := range ops {
+		target := op.DocumentID
+		if op.Save != nil {
+			target = op.Save.URL
+		}
+
+		status := "pending"
+		switch {
+		case op.Failed:
+			status = "failed: " + op.LastError
+		case op.LastError != "":
+			status = "retrying: " + op.LastError
+		}
+
+		t.add(op.ID, string(op.Kind), target, op.QueuedAt.Local().Format(time.DateTime), strconv.Itoa(op.Attempts), status)
+	}
+
+	return c.writeTable(t)
+}
+
+type queueFlushCmd struct {
+	*queueCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newQueueFlushCmd(parent *queueCmd) *queueFlushCmd {
+	cmd := &queueFlushCmd{queueCmd: parent}
+	cmd.flags = ff.NewFlagSet("flush").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "flush",
+		Usage:     "readerctl queue flush",
+		ShortHelp: "send queued operations to Reader",
+		LongHelp: `Operations Reader rejects are marked failed and left in the queue, drop
+them with readerctl queue drop or send them again with readerctl queue retry.
+Flushing stops when Reader can't be reached or rate limits, run it again
+later.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *queueFlushCmd) exec(ctx context.Context, args []string) error {
+	queue, err := c.queue()
+	if err != nil {
+		return err
+	}
+
+	result, err := queue.Flush(ctx)
+	fmt.Fprintf(c.stderr, "sent %d, failed %d, %d remaining\n", result.Sent, result.Failed, result.Remaining)
+	if err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+
+	return nil
+}
+
+type queueRetryCmd struct {
+	*queueCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newQueueRetryCmd(parent *queueCmd) *queueRetryCmd {
+	cmd := &queueRetryCmd{queueCmd: parent}
+	cmd.flags = ff.NewFlagSet("retry").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "retry",
+		Usage:     "readerctl queue retry [<OPERATION-ID> ...]",
+		ShortHelp: "send failed operations again on the next flush",
+		LongHelp:  "Without operation IDs every failed operation is sent again.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *queueRetryCmd) exec(ctx context.Context, args []string) error {
+	n, err := sync.NewQueue(nil, c.store()).Retry(ctx, args...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "%d operations will be sent again\n", n)
+	return nil
+}
+
+type queueDropCmd struct {
+	*queueCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newQueueDropCmd(parent *queueCmd) *queueDropCmd {
+	cmd := &queueDropCmd{queueCmd: parent}
+	cmd.flags = ff.NewFlagSet("drop").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "drop",
+		Usage:     "readerctl queue drop <OPERATION-ID> ...",
+		ShortHelp: "remove queued operations without sending them",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *queueDropCmd) exec(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected at least one operation ID")
+	}
+
+	return c.store().RemoveOperations(ctx, args)
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type readCmd struct {
+	*rootCmd
+	noPager        bool
+	updateProgress bool
+	flags          *ff.FlagSet
+	command        *ff.Command
+}
+
+func newReadCmd(root *rootCmd) *readCmd {
+	cmd := &readCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("read").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.noPager, 0, "no-pager", "print the document instead of paging it")
+	cmd.flags.BoolVar(&cmd.updateProgress, 0, "update-progress", "save how far you read as the reading progress when quitting the pager")
+	cmd.command = &ff.Command{
+		Name:      "read",
+		Usage:     "readerctl read [FLAGS] <ID>",
+		ShortHelp: "read a document in the terminal",
+		LongHelp: `Fetches the content of the document and pages it rendered as Markdown,
+starting from its reading progress. When not writing to a terminal plain
+Markdown is printed instead.
+
+Keys: j/k or ↑/↓ scroll a line, space and b a page, d and u half a page, g
+and G jump to the start and the end, q quits.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *readCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return &usageError{err: errors.New("expected exactly one document ID")}
+	}
+
+	doc, err := c.fetchDocument(ctx, args[0], true)
+	if err != nil {
+		return err
+	}
+
+	markdown := c.documentReadable(ctx, *doc)
+
+	if !isTerminal(c.stdout) {
+		_, err := fmt.Fprint(c.stdout, markdown)
+		return err
+	}
+
+	style := styles.NoTTYStyle
+	if useColor(c.stdout) {
+		style = styles.LightStyle
+		if lipgloss.HasDarkBackground() {
+			style = styles.DarkStyle
+		}
+	}
+
+	if c.noPager {
+		rendered, err := renderMarkdown(markdown, style, 80)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(c.stdout, rendered)
+		return err
+	}
+
+	m := &readerModel{
+		title:    cmp.Or(doc.Title, doc.ID),
+		markdown: markdown,
+		style:    style,
+		start:    doc.ReadingProgress,
+	}
+	if _, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx), tea.WithInput(c.stdin), tea.WithOutput(c.stdout)).Run(); err != nil {
+		return err
+	}
+	if m.err != nil {
+		return m.err
+	}
+
+	if !c.updateProgress || m.furthest <= doc.ReadingProgress {
+		return nil
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.Update(ctx, doc.ID, readwisereader.UpdateParams{ReadingProgress: &m.furthest}); err != nil {
+		return fmt.Errorf("update reading progress: %w", err)
+	}
+
+	fmt.Fprintf(c.stderr, "reading progress %.0f%%\n", m.furthest*100)
+	return nil
+}
+
+// documentReadable renders doc as Markdown to be read, its title and a line
+// of metadata followed by the content, or the summary when there is none.
+func (c *readCmd) documentReadable(ctx context.Context, doc readwisereader.Document) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", cmp.Or(doc.Title, doc.ID))
+
+	var meta []string
+	for _, s := range []string{documentAuthor(doc), documentSite(doc)} {
+		if s != "" {
+			meta = append(meta, s)
+		}
+	}
+	if doc.WordCount > 0 {
+		meta = append(meta, formatReadingTime(readingTime(doc.WordCount, c.wpm)))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(&b, "*%s*\n\n", strings.Join(meta, " · "))
+	}
+
+	content := htmlMarkdown(c.documentHTML(ctx, doc))
+	if content == "" {
+		content = strings.TrimSpace(doc.Summary)
+	}
+	fmt.Fprintf(&b, "%s\n", content)
+
+	return b.String()
+}
+
+func renderMarkdown(markdown, style string, width int) (string, error) {
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle(style), glamour.WithWordWrap(width))
+	if err != nil {
+		return "", err
+	}
+
+	return r.Render(markdown)
+}
+
+// readerModel pages a Markdown document, rendered anew for the width of the
+// terminal.
+type readerModel struct {
+	title    string
+	markdown string
+	style    string
+	// start is the reading progress to open the document at.
+	start float64
+
+	lines  []string
+	offset int
+	width  int
+	height int
+
+	// furthest is the share of the document read so far.
+	furthest float64
+	err      error
+}
+
+func (m *readerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *readerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Keep the place in the document across re-rendering.
+		position := m.start
+		if m.lines != nil {
+			position = float64(m.offset) / float64(len(m.lines))
+		}
+
+		m.width, m.height = msg.Width, msg.Height
+		rendered, err := renderMarkdown(m.markdown, m.style, max(min(m.width, 100)-2, 20))
+		if err != nil {
+			m.err = err
+			return m, tea.Quit
+		}
+
+		// Only scrolling counts as reading.
+		furthest := m.furthest
+		m.lines = strings.Split(strings.TrimRight(rendered, "\n"), "\n")
+		m.scroll(int(position * float64(len(m.lines))))
+		m.furthest = furthest
+	case tea.KeyMsg:
+		page := m.rows()
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "down", "j", "enter":
+			m.scroll(m.offset + 1)
+		case "up", "k":
+			m.scroll(m.offset - 1)
+		case " ", "f", "pgdown":
+			m.scroll(m.offset + page)
+		case "b", "pgup":
+			m.scroll(m.offset - page)
+		case "d", "ctrl+d":
+			m.scroll(m.offset + page/2)
+		case "u", "ctrl+u":
+			m.scroll(m.offset - page/2)
+		case "g", "home":
+			m.scroll(0)
+		case "G", "end":
+			m.scroll(len(m.lines))
+		}
+	}
+
+	return m, nil
+}
+
+// rows is the number of lines shown a page, leaving one for the status line.
+func (m *readerModel) rows() int {
+	return max(m.height-1, 1)
+}
+
+// scroll moves to offset, within the document, and records how far it was
+// read.
+func (m *readerModel) scroll(offset int) {
+	m.offset = max(min(offset, len(m.lines)-m.rows()), 0)
+
+	if read := min(float64(m.offset+m.rows())/float64(len(m.lines)), 1); read > m.furthest {
+		m.furthest = read
+	}
+}
+
+func (m *readerModel) View() string {
+	if m.lines == nil {
+		return ""
+	}
+
+	end := min(m.offset+m.rows(), len(m.lines))
+	lines := append([]string{}, m.lines[m.offset:end]...)
+	for len(lines) < m.rows() {
+		lines = append(lines, "")
+	}
+
+	status := fmt.Sprintf("%s · %.0f%%", m.title, min(float64(end)/float64(len(m.lines)), 1)*100)
+	lines = append(lines, boardFaintStyle.Render(truncateRunes(status, max(m.width, 1))))
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const defaultWPM = 250
+
+// readingTime estimates how long reading the given number of words takes at
+// wpm words per minute, rounded up to whole minutes.
+func readingTime(words, wpm int) time.Duration {
+	if words <= 0 || wpm <= 0 {
+		return 0
+	}
+
+	minutes := (words + wpm - 1) / wpm
+	return time.Duration(minutes) * time.Minute
+}
+
+func formatReadingTime(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d min", int(d.Minutes()))
+}
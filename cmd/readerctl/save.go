@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/readability"
+	"code.selman.me/go-readwisereader/sync"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type saveCmd struct {
+	*rootCmd
+	htmlFile        string
+	stdinHTML       bool
+	clean           bool
+	baseURL         string
+	shouldCleanHTML bool
+	skipExisting    bool
+	queue           bool
+	fromFile        string
+	title           string
+	author          string
+	summary         string
+	notes           string
+	tags            string
+	location        string
+	flags           *ff.FlagSet
+	command         *ff.Command
+}
+
+func newSaveCmd(root *rootCmd) *saveCmd {
+	cmd := &saveCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("save").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.htmlFile, 0, "html", "", "save the content of this local HTML file instead of letting Reader fetch the URL")
+	cmd.flags.BoolVar(&cmd.stdinHTML, 0, "stdin-html", "like --html, reading the HTML from stdin")
+	cmd.flags.BoolVar(&cmd.clean, 0, "clean", "strip scripts and trackers from --html, keeping only essential styles inlined, before saving")
+	cmd.flags.StringVar(&cmd.baseURL, 0, "base-url", "", "resolve relative links in --html against this URL, defaults to the saved URL")
+	cmd.flags.BoolVar(&cmd.shouldCleanHTML, 0, "should-clean-html", "ask Reader to clean up --html as well")
+	cmd.flags.BoolVar(&cmd.skipExisting, 0, "skip-existing", "don't save URLs that are already in the library")
+	cmd.flags.StringVar(&cmd.title, 0, "title", "", "title of the document, instead of the one Reader finds")
+	cmd.flags.StringVar(&cmd.author, 0, "author", "", "author of the document")
+	cmd.flags.StringVar(&cmd.summary, 0, "summary", "", "summary of the document")
+	cmd.flags.StringVar(&cmd.notes, 0, "notes", "", "note attached to the document")
+	cmd.flags.StringVar(&cmd.tags, 0, "tags", "", "tags to add, comma separated")
+	cmd.flags.StringVar(&cmd.location, 0, "location", "", "location to save to: new, later, shortlist, archive or feed")
+	cmd.flags.StringVar(&cmd.fromFile, 0, "from-file", "", "save the URLs listed in this file, one per line")
+	cmd.flags.BoolVar(&cmd.queue, 0, "queue", "store the save in the local queue instead of sending it, see readerctl queue")
+	cmd.command = &ff.Command{
+		Name:      "save",
+		Usage:     "readerctl save [FLAGS] <URL> ...",
+		ShortHelp: "save a URL to Reader",
+		LongHelp: `Without URLs, or with -, URLs are read from stdin, one per line. Blank lines
+and lines starting with # are ignored. Several URLs are saved concurrently,
+within the API rate limits.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+// saveResult is the outcome of saving one URL.
+type saveResult struct {
+	URL       string `json:"url"`
+	ID        string `json:"id"`
+	ReaderURL string `json:"reader_url,omitempty"`
+	Skipped   bool   `json:"skipped,omitempty"`
+	// Set when Reader had the URL already, without --skip-existing
+	AlreadyExists bool `json:"already_exists,omitempty"`
+	// ID of the queued operation with --queue
+	Operation string `json:"operation,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (c *saveCmd) exec(ctx context.Context, args []string) error {
+	if c.stdinHTML {
+		if c.htmlFile != "" {
+			return errors.New("--html and --stdin-html can't be used together")
+		}
+		// Stdin holds the content, the URL has to be an argument.
+		if len(args) != 1 || args[0] == "-" || c.fromFile != "" {
+			return errors.New("--stdin-html takes exactly one URL argument")
+		}
+	}
+
+	args, err := c.urls(args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New("expected at least one URL")
+	}
+
+	if c.htmlFile != "" && len(args) != 1 {
+		return errors.New("--html takes exactly one URL")
+	}
+
+	if (c.title != "" || c.summary != "") && len(args) != 1 {
+		return errors.New("--title and --summary take exactly one URL")
+	}
+
+	if c.location != "" {
+		if _, err := parseLocation(c.location); err != nil {
+			return &usageError{err: err}
+		}
+	}
+
+	if c.htmlFile == "" && !c.stdinHTML && (c.clean || c.baseURL != "" || c.shouldCleanHTML) {
+		return errors.New("--clean, --base-url and --should-clean-html require --html")
+	}
+
+	if c.output != outputTable && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	// Queued saves work offline, unless existing documents are looked up.
+	var client *readwisereader.Client
+	if !c.queue || c.skipExisting {
+		var err error
+		client, err = c.client()
+		if err != nil {
+			return err
+		}
+	}
+	queue := sync.NewQueue(client, c.store())
+
+	var existing map[string]*readwisereader.Document
+	if c.skipExisting {
+		var err error
+		existing, err = findExisting(ctx, client, args)
+		if err != nil {
+			return fmt.Errorf("look up existing documents: %w", err)
+		}
+	}
+
+	var results []saveResult
+	var batch []readwisereader.SaveParams
+	for _, u := range args {
+		if doc := existing[u]; doc != nil {
+			result := saveResult{URL: u, ID: doc.ID, ReaderURL: doc.URL, Skipped: true}
+			results = append(results, result)
+			c.printSaveResult(result)
+			continue
+		}
+
+		params, err := c.saveParams(u)
+		if err != nil {
+			return err
+		}
+
+		if !c.queue && len(args) > 1 {
+			batch = append(batch, params)
+			continue
+		}
+
+		if c.queue {
+			op, err := queue.Save(ctx, params)
+			if err != nil {
+				return fmt.Errorf("queue %s: %w", u, err)
+			}
+
+			result := saveResult{URL: u, Operation: op.ID}
+			results = append(results, result)
+			c.printSaveResult(result)
+			continue
+		}
+
+		resp, err := client.Save(ctx, params)
+		if err != nil {
+			return fmt.Errorf("save %s: %w", u, err)
+		}
+
+		result := saveResult{URL: u, ID: resp.ID, ReaderURL: resp.URL, AlreadyExists: resp.AlreadyExists}
+		results = append(results, result)
+		c.printSaveResult(result)
+	}
+
+	var failed int
+	if len(batch) > 0 {
+		saved, err := c.saveBatch(ctx, client, batch)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range saved {
+			if result.Error != "" {
+				failed++
+			}
+		}
+		results = append(results, saved...)
+	}
+
+	if c.output == outputJSON {
+		if err := writeJSON(c.stdout, results); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d saves failed", failed, len(batch))
+	}
+
+	return nil
+}
+
+// urls returns the URLs to save, the arguments or, without any or with -,
+// those listed in --from-file or on stdin.
+func (c *saveCmd) urls(args []string) ([]string, error) {
+	var r io.Reader
+	switch {
+	case c.fromFile != "":
+		if len(args) > 0 {
+			return nil, &usageError{err: errors.New("--from-file takes no URL arguments")}
+		}
+
+		f, err := os.Open(c.fromFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	case len(args) == 0 || len(args) == 1 && args[0] == "-":
+		r = c.stdin
+	default:
+		return args, nil
+	}
+
+	var urls []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			urls = append(urls, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read URLs: %w", err)
+	}
+
+	return urls, nil
+}
+
+func (c *saveCmd) saveParams(u string) (readwisereader.SaveParams, error) {
+	params := readwisereader.SaveParams{
+		URL:     u,
+		Title:   optional(c.title),
+		Author:  optional(c.author),
+		Summary: optional(c.summary),
+		Notes:   optional(c.notes),
+	}
+
+	for _, tag := range strings.Split(c.tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+
+	if c.location != "" {
+		// Checked in exec.
+		params.Location, _ = parseLocation(c.location)
+	}
+	if c.htmlFile != "" || c.stdinHTML {
+		content, err := c.readHTML(u)
+		if err != nil {
+			return params, err
+		}
+
+		params.HTML = &content
+		if c.shouldCleanHTML {
+			params.ShouldCleanHTML = &c.shouldCleanHTML
+		}
+	}
+
+	return params, nil
+}
+
+// saveBatch saves several URLs at once, reporting each result as it comes
+// in and the totals at the end. Failed saves are reported in the results.
+func (c *saveCmd) saveBatch(ctx context.Context, client *readwisereader.Client, batch []readwisereader.SaveParams) ([]saveResult, error) {
+	progress := !c.quiet && isTerminal(c.stderr)
+
+	var done, created, existing, failed int
+	results, err := client.SaveBatch(ctx, batch, readwisereader.OnSaved(func(r readwisereader.SaveResult) {
+		done++
+		if progress {
+			// Clear the progress line before anything else is printed.
+			fmt.Fprint(c.stderr, "\r\x1b[K")
+		}
+
+		switch {
+		case r.Err != nil:
+			failed++
+			fmt.Fprintf(c.stderr, "save %s: %v\n", r.Params.URL, r.Err)
+		case r.Response.AlreadyExists:
+			existing++
+		default:
+			created++
+		}
+		if r.Err == nil {
+			c.printSaveResult(batchSaveResult(r))
+		}
+
+		if progress {
+			fmt.Fprintf(c.stderr, "saving %d/%d", done, len(batch))
+		}
+	}))
+	if progress {
+		fmt.Fprint(c.stderr, "\r\x1b[K")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(c.stderr, "saved %d, %d already in Reader, failed %d\n", created, existing, failed)
+
+	saved := make([]saveResult, 0, len(results))
+	for _, r := range results {
+		saved = append(saved, batchSaveResult(r))
+	}
+
+	return saved, nil
+}
+
+// optional returns nil for an empty flag value, so it isn't sent.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+func batchSaveResult(r readwisereader.SaveResult) saveResult {
+	if r.Err != nil {
+		return saveResult{URL: r.Params.URL, Error: r.Err.Error()}
+	}
+
+	return saveResult{URL: r.Params.URL, ID: r.Response.ID, ReaderURL: r.Response.URL, AlreadyExists: r.Response.AlreadyExists}
+}
+
+// printSaveResult reports a result as soon as it is known so that a long
+// bulk save shows progress, JSON output is written once at the end.
+func (c *saveCmd) printSaveResult(r saveResult) {
+	if c.output != outputTable {
+		return
+	}
+
+	if r.Operation != "" {
+		fmt.Fprintf(c.stdout, "%s\tqueued as %s\n", r.URL, r.Operation)
+		return
+	}
+
+	if r.Skipped {
+		fmt.Fprintf(c.stdout, "%s\tskipped (already saved as %s)\n", r.URL, r.ID)
+		return
+	}
+
+	if r.AlreadyExists {
+		fmt.Fprintf(c.stdout, "%s\t%s\t(already saved)\n", r.ID, r.ReaderURL)
+		return
+	}
+
+	fmt.Fprintf(c.stdout, "%s\t%s\n", r.ID, r.ReaderURL)
+}
+
+// findExisting maps each of urls that is already saved to its document. A
+// single URL goes through ExistsByURL, more are matched in one walk of the
+// library rather than one per URL.
+func findExisting(ctx context.Context, client *readwisereader.Client, urls []string) (map[string]*readwisereader.Document, error) {
+	existing := make(map[string]*readwisereader.Document, len(urls))
+	if len(urls) == 1 {
+		doc, err := client.ExistsByURL(ctx, urls[0])
+		if err != nil {
+			return nil, err
+		}
+
+		existing[urls[0]] = doc
+		return existing, nil
+	}
+
+	for page, err := range client.ListPaginate(ctx, readwisereader.ListParams{}) {
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range page.Results {
+			if doc.ParentID != "" {
+				continue
+			}
+
+			for _, u := range urls {
+				if existing[u] == nil && doc.HasURL(u) {
+					existing[u] = &doc
+				}
+			}
+		}
+
+		if len(existing) == len(urls) {
+			break
+		}
+	}
+
+	return existing, nil
+}
+
+func (c *saveCmd) readHTML(saveURL string) (string, error) {
+	name := c.htmlFile
+	var content []byte
+	var err error
+	if c.stdinHTML {
+		name = "stdin"
+		content, err = io.ReadAll(c.stdin)
+	} else {
+		content, err = os.ReadFile(c.htmlFile)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(bytes.TrimSpace(content)) == 0 {
+		return "", fmt.Errorf("%s: no HTML to save", name)
+	}
+
+	if !c.clean {
+		return string(content), nil
+	}
+
+	base := c.baseURL
+	if base == "" {
+		base = saveURL
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("base URL: %w", err)
+	}
+
+	cleaned, err := readability.Clean(bytes.NewReader(content), u)
+	if err != nil {
+		return "", fmt.Errorf("clean %s: %w", name, err)
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+)
+
+// script runs the Starlark file given with --script. Scripts may define any
+// of:
+//
+//	filter(doc)      return false to leave a document out of list
+//	transform(doc)   return a line to print for a document instead of the
+//	                 list table, non-string values are printed as JSON
+//	on_event(event)  called for every sync event during sync and daemon
+//
+// Documents are dicts with snake_case keys as in the API, events are dicts
+// with a "type" and "document". Scripts can call update(id, location=,
+// tags=, title=, notes=) to change a document, and use the json module.
+type script struct {
+	path    string
+	thread  *starlark.Thread
+	globals starlark.StringDict
+}
+
+func (r *rootCmd) loadScript(ctx context.Context, client *readwisereader.Client) (*script, error) {
+	if r.script == "" {
+		return nil, nil
+	}
+
+	s := &script{path: r.script}
+	s.thread = &starlark.Thread{
+		Name: "readerctl",
+		Print: func(_ *starlark.Thread, msg string) {
+			fmt.Fprintln(r.stderr, msg)
+		},
+	}
+	context.AfterFunc(ctx, func() { s.thread.Cancel("canceled") })
+
+	predeclared := starlark.StringDict{
+		"json":   starlarkjson.Module,
+		"update": starlark.NewBuiltin("update", scriptUpdate(ctx, client)),
+	}
+
+	globals, err := starlark.ExecFile(s.thread, r.script, nil, predeclared)
+	if err != nil {
+		return nil, fmt.Errorf("script: %w", err)
+	}
+
+	s.globals = globals
+	return s, nil
+}
+
+func (s *script) has(name string) bool {
+	if s == nil {
+		return false
+	}
+
+	_, ok := s.globals[name].(starlark.Callable)
+	return ok
+}
+
+func (s *script) call(name string, arg starlark.Value) (starlark.Value, error) {
+	v, err := starlark.Call(s.thread, s.globals[name], starlark.Tuple{arg}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %s: %w", s.path, name, err)
+	}
+
+	return v, nil
+}
+
+// filter reports whether doc is kept, documents are kept without a filter.
+func (s *script) filter(doc readwisereader.Document) (bool, error) {
+	if !s.has("filter") {
+		return true, nil
+	}
+
+	v, err := s.call("filter", documentValue(doc))
+	if err != nil {
+		return false, err
+	}
+
+	return bool(v.Truth()), nil
+}
+
+func (s *script) transform(doc readwisereader.Document) (string, error) {
+	v, err := s.call("transform", documentValue(doc))
+	if err != nil {
+		return "", err
+	}
+
+	if str, ok := starlark.AsString(v); ok {
+		return str, nil
+	}
+
+	encoded, err := starlark.Call(s.thread, starlarkjson.Module.Members["encode"], starlark.Tuple{v}, nil)
+	if err != nil {
+		return "", fmt.Errorf("script %s: transform: %w", s.path, err)
+	}
+
+	str, _ := starlark.AsString(encoded)
+	return str, nil
+}
+
+// subscribe hands the sync events of syncer to on_event, if the script
+// defines it. Errors are reported on stderr and don't stop the sync.
+func (s *script) subscribe(syncer *sync.Syncer, report func(error)) {
+	if !s.has("on_event") {
+		return
+	}
+
+	syncer.Subscribe(func(ctx context.Context, event sync.Event) {
+		if _, err := s.call("on_event", eventValue(event)); err != nil {
+			report(err)
+		}
+	})
+}
+
+func (r *rootCmd) reportScriptError(err error) {
+	fmt.Fprintf(r.stderr, "warning: %v\n", err)
+}
+
+func documentValue(doc readwisereader.Document) *starlark.Dict {
+	tags := make([]string, 0, len(doc.Tags))
+	for tag := range doc.Tags {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	tagValues := make([]starlark.Value, len(tags))
+	for i, tag := range tags {
+		tagValues[i] = starlark.String(tag)
+	}
+
+	formatTime := func(t time.Time) starlark.Value {
+		if t.IsZero() {
+			return starlark.None
+		}
+		return starlark.String(t.Format(time.RFC3339))
+	}
+
+	d := starlark.NewDict(20)
+	for k, v := range map[string]starlark.Value{
+		"id":               starlark.String(doc.ID),
+		"url":              starlark.String(doc.URL),
+		"source_url":       starlark.String(doc.SourceURL),
+		"title":            starlark.String(doc.Title),
+		"author":           starlark.String(doc.Author),
+		"source":           starlark.String(doc.Source),
+		"category":         starlark.String(doc.Category),
+		"location":         starlark.String(doc.Location),
+		"tags":             starlark.NewList(tagValues),
+		"site_name":        starlark.String(doc.SiteName),
+		"word_count":       starlark.MakeInt(doc.WordCount),
+		"reading_progress": starlark.Float(doc.ReadingProgress),
+		"summary":          starlark.String(doc.Summary),
+		"notes":            starlark.String(doc.Notes),
+		"parent_id":        starlark.String(doc.ParentID),
+		"created_at":       formatTime(doc.CreatedAt),
+		"updated_at":       formatTime(doc.UpdatedAt),
+		"published_date":   formatTime(doc.PublishedDate),
+		"saved_at":         formatTime(doc.SavedAt),
+	} {
+		d.SetKey(starlark.String(k), v)
+	}
+
+	return d
+}
+
+func eventValue(event sync.Event) *starlark.Dict {
+	d := starlark.NewDict(4)
+	fields := eventJSON(event)
+	for k, v := range fields {
+		var value starlark.Value
+		switch v := v.(type) {
+		case readwisereader.Document:
+			value = documentValue(v)
+		case readwisereader.Location:
+			value = starlark.String(v)
+		case string:
+			value = starlark.String(v)
+		case sync.Result:
+			r := starlark.NewDict(2)
+			r.SetKey(starlark.String("documents"), starlark.MakeInt(v.Documents))
+			r.SetKey(starlark.String("deleted"), starlark.MakeInt(v.Deleted))
+			value = r
+		default:
+			continue
+		}
+		d.SetKey(starlark.String(k), value)
+	}
+
+	return d
+}
+
+func scriptUpdate(ctx context.Context, client *readwisereader.Client) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			id, location, title, notes string
+			tags                       *starlark.List
+		)
+		err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+			"id", &id,
+			"location?", &location,
+			"tags?", &tags,
+			"title?", &title,
+			"notes?", &notes,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if client == nil {
+			return nil, fmt.Errorf("%s: no client available", fn.Name())
+		}
+
+		var params readwisereader.UpdateParams
+		if location != "" {
+			params.SetLocation(readwisereader.Location(location))
+		}
+		if title != "" {
+			params.SetTitle(title)
+		}
+		if notes != "" {
+			params.SetNotes(notes)
+		}
+		if tags != nil {
+			names := make([]string, 0, tags.Len())
+			for v := range tags.Elements() {
+				name, ok := starlark.AsString(v)
+				if !ok {
+					return nil, fmt.Errorf("%s: tags must be strings, got %s", fn.Name(), v.Type())
+				}
+				names = append(names, name)
+			}
+			params.SetTags(names...)
+		}
+
+		if _, err := client.Update(ctx, id, params); err != nil {
+			return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+		}
+
+		return starlark.None, nil
+	}
+}
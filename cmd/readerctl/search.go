@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"code.selman.me/go-readwisereader/sync/sqlitestore"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type searchCmd struct {
+	*rootCmd
+	location string
+	category string
+	tags     []string
+	content  bool
+	limit    int
+	format   string
+	flags    *ff.FlagSet
+	command  *ff.Command
+}
+
+func newSearchCmd(root *rootCmd) *searchCmd {
+	cmd := &searchCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("search").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.location, 0, "location", "", "only search documents in these locations, comma separated")
+	cmd.flags.StringVar(&cmd.category, 0, "category", "", "only search documents of these categories, comma separated")
+	cmd.flags.StringListVar(&cmd.tags, 0, "tag", "only search documents with this tag, repeatable")
+	cmd.flags.BoolVar(&cmd.content, 0, "content", "search the html content too, stored by sync --sqlite --with-html")
+	cmd.flags.IntVar(&cmd.limit, 0, "max-results", 0, "return at most this many documents, 0 for all")
+	cmd.flags.StringVar(&cmd.format, 0, "format", "", formatFlagHelp)
+	cmd.command = &ff.Command{
+		Name:      "search",
+		Usage:     "readerctl search [FLAGS] <QUERY>",
+		ShortHelp: "search documents in the local mirror",
+		LongHelp: `Searches titles, authors, summaries, notes and tags in the SQLite mirror,
+run readerctl sync --sqlite first. Documents match when they contain every
+word of the query, a word ending in * matches as a prefix.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *searchCmd) exec(ctx context.Context, args []string) error {
+	if !isTabular(c.output) && c.output != outputJSON && c.output != outputJSONL {
+		return unsupportedOutput(c.output)
+	}
+
+	query := strings.Join(args, " ")
+	if strings.TrimSpace(query) == "" && c.location == "" && c.category == "" && len(c.tags) == 0 {
+		return &usageError{err: errors.New("expected a query")}
+	}
+
+	locations, err := parseLocations(c.location)
+	if err != nil {
+		return &usageError{err: err}
+	}
+
+	categories, err := parseCategories(c.category)
+	if err != nil {
+		return &usageError{err: err}
+	}
+
+	format, err := parseFormat(c.format)
+	if err != nil {
+		return err
+	}
+
+	store, err := c.mirror(false)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	docs, err := store.Search(ctx, sqlitestore.Query{
+		Text:       query,
+		Content:    c.content,
+		Locations:  locations,
+		Categories: categories,
+		Tags:       c.tags,
+		Limit:      c.limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.writeDocuments(docs, format)
+}
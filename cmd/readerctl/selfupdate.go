@@ -0,0 +1,462 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	releasesURL = "https://api.github.com/repos/seruman/go-readwisereader/releases"
+	// maxReleaseAsset bounds the size of a downloaded release archive.
+	maxReleaseAsset = 100 << 20
+)
+
+var (
+	// version is set at build time with -ldflags "-X main.version=v1.2.3".
+	version = ""
+	// releasePublicKey is the base64 ed25519 key release checksums are signed
+	// with, set at build time like version. Without it only checksums are
+	// verified.
+	releasePublicKey = ""
+)
+
+// currentVersion returns the version readerctl was built as, falling back to
+// the module version for go install builds.
+func currentVersion() string {
+	if version != "" {
+		return version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return "dev"
+}
+
+type selfupdateCmd struct {
+	*rootCmd
+	channel string
+	check   bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newSelfupdateCmd(root *rootCmd) *selfupdateCmd {
+	cmd := &selfupdateCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("selfupdate").SetParent(root.flags)
+	cmd.flags.StringEnumVar(&cmd.channel, 0, "channel", "release channel, prerelease includes release candidates", "stable", "prerelease")
+	cmd.flags.BoolVar(&cmd.check, 0, "check", "only report whether an update is available")
+	cmd.command = &ff.Command{
+		Name:      "selfupdate",
+		Usage:     "readerctl selfupdate [FLAGS]",
+		ShortHelp: "update readerctl to the latest release",
+		LongHelp: `Downloads the release archive for this platform, verifies it against the
+release checksums, and their signature when the build embeds a release key,
+then replaces the running binary.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type release struct {
+	TagName    string         `json:"tag_name"`
+	Prerelease bool           `json:"prerelease"`
+	Draft      bool           `json:"draft"`
+	Assets     []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+func (r *release) asset(name string) *releaseAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+
+	return nil
+}
+
+func (c *selfupdateCmd) exec(ctx context.Context, args []string) error {
+	client := &http.Client{Timeout: 5 * time.Minute}
+
+	rel, err := c.latestRelease(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	current := currentVersion()
+	if compareVersions(rel.TagName, current) <= 0 {
+		fmt.Fprintf(c.stderr, "readerctl %s is up to date\n", current)
+		return nil
+	}
+
+	if c.check {
+		fmt.Fprintf(c.stdout, "%s\n", rel.TagName)
+		fmt.Fprintf(c.stderr, "readerctl %s is available, running %s\n", rel.TagName, current)
+		return nil
+	}
+
+	archive := releaseArchiveName(rel.TagName)
+	asset := rel.asset(archive)
+	if asset == nil {
+		return fmt.Errorf("release %s has no build for %s/%s", rel.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+
+	checksums, err := c.releaseChecksums(ctx, client, rel)
+	if err != nil {
+		return err
+	}
+
+	want, ok := checksums[archive]
+	if !ok {
+		return fmt.Errorf("release %s has no checksum for %s", rel.TagName, archive)
+	}
+
+	b, err := download(ctx, client, asset.URL)
+	if err != nil {
+		return err
+	}
+
+	if sum := sha256.Sum256(b); hex.EncodeToString(sum[:]) != want {
+		return fmt.Errorf("%s: checksum mismatch", archive)
+	}
+
+	binary, err := extractBinary(archive, b)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceExecutable(binary); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "updated readerctl %s to %s\n", current, rel.TagName)
+	return nil
+}
+
+func (c *selfupdateCmd) latestRelease(ctx context.Context, client *http.Client) (*release, error) {
+	if c.channel == "stable" {
+		var rel release
+		if err := getReleaseJSON(ctx, client, releasesURL+"/latest", &rel); err != nil {
+			return nil, err
+		}
+		return &rel, nil
+	}
+
+	var releases []release
+	if err := getReleaseJSON(ctx, client, releasesURL, &releases); err != nil {
+		return nil, err
+	}
+
+	// Releases are listed newest first.
+	for _, rel := range releases {
+		if !rel.Draft {
+			return &rel, nil
+		}
+	}
+
+	return nil, errors.New("no releases published")
+}
+
+// releaseChecksums returns the sha256 sums of the release assets by name,
+// verifying their signature when a release key is embedded.
+func (c *selfupdateCmd) releaseChecksums(ctx context.Context, client *http.Client, rel *release) (map[string]string, error) {
+	asset := rel.asset("checksums.txt")
+	if asset == nil {
+		return nil, fmt.Errorf("release %s has no checksums", rel.TagName)
+	}
+
+	b, err := download(ctx, client, asset.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if releasePublicKey != "" {
+		if err := verifyChecksums(ctx, client, rel, b); err != nil {
+			return nil, err
+		}
+	}
+
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		sum, name, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "  ")
+		if ok {
+			sums[strings.TrimSpace(name)] = sum
+		}
+	}
+
+	return sums, scanner.Err()
+}
+
+func verifyChecksums(ctx context.Context, client *http.Client, rel *release, checksums []byte) error {
+	key, err := base64.StdEncoding.DecodeString(releasePublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid embedded release key")
+	}
+
+	asset := rel.asset("checksums.txt.sig")
+	if asset == nil {
+		return fmt.Errorf("release %s has no checksum signature", rel.TagName)
+	}
+
+	b, err := download(ctx, client, asset.URL)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return fmt.Errorf("decode checksum signature: %w", err)
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(key), checksums, sig) {
+		return errors.New("checksum signature mismatch")
+	}
+
+	return nil
+}
+
+// releaseArchiveName is the name of the release archive for this platform.
+func releaseArchiveName(tag string) string {
+	ext := ".tar.gz"
+	if runtime.GOOS == "windows" {
+		ext = ".zip"
+	}
+
+	return fmt.Sprintf("readerctl_%s_%s_%s%s", strings.TrimPrefix(tag, "v"), runtime.GOOS, runtime.GOARCH, ext)
+}
+
+func getReleaseJSON(ctx context.Context, client *http.Client, u string, v any) error {
+	b, err := download(ctx, client, u)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("decode releases: %w", err)
+	}
+
+	return nil
+}
+
+func download(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: unexpected status code: %d", u, resp.StatusCode)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxReleaseAsset+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > maxReleaseAsset {
+		return nil, fmt.Errorf("get %s: too large", u)
+	}
+
+	return b, nil
+}
+
+// extractBinary returns the readerctl binary from a release archive.
+func extractBinary(name string, archive []byte) ([]byte, error) {
+	binary := "readerctl"
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) != binary {
+				continue
+			}
+
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+
+			return io.ReadAll(io.LimitReader(rc, maxReleaseAsset))
+		}
+
+		return nil, fmt.Errorf("%s: no %s in archive", name, binary)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: no %s in archive", name, binary)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == binary {
+			return io.ReadAll(io.LimitReader(tr, maxReleaseAsset))
+		}
+	}
+}
+
+// replaceExecutable swaps the running binary for binary. The new binary is
+// written next to the old one and renamed over it, so a failed update leaves
+// the old one in place.
+func replaceExecutable(binary []byte) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".readerctl-update-*")
+	if err != nil {
+		return fmt.Errorf("replace %s: %w", exe, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+
+	// Windows won't replace a running executable, but lets it be renamed.
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), exe)
+}
+
+// compareVersions compares two vMAJOR.MINOR.PATCH[-PRE] versions, ordering
+// versions that don't parse, dev builds included, before everything else.
+func compareVersions(a, b string) int {
+	pa, oka := parseVersion(a)
+	pb, okb := parseVersion(b)
+	switch {
+	case !oka && !okb:
+		return strings.Compare(a, b)
+	case !oka:
+		return -1
+	case !okb:
+		return 1
+	}
+
+	for i := range 3 {
+		if pa.parts[i] != pb.parts[i] {
+			if pa.parts[i] < pb.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A pre-release comes before the release it leads up to.
+	switch {
+	case pa.pre == pb.pre:
+		return 0
+	case pa.pre == "":
+		return 1
+	case pb.pre == "":
+		return -1
+	default:
+		return strings.Compare(pa.pre, pb.pre)
+	}
+}
+
+type parsedVersion struct {
+	parts [3]int
+	pre   string
+}
+
+func parseVersion(v string) (parsedVersion, bool) {
+	var p parsedVersion
+
+	v, ok := strings.CutPrefix(v, "v")
+	if !ok {
+		return p, false
+	}
+
+	v, p.pre, _ = strings.Cut(v, "-")
+	v, _, _ = strings.Cut(v, "+")
+
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return p, false
+	}
+
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return p, false
+		}
+		p.parts[i] = n
+	}
+
+	return p, true
+}
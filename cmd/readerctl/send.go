@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/contentfetch"
+	"code.selman.me/go-readwisereader/epub"
+	"code.selman.me/go-readwisereader/readability"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type sendCmd struct {
+	*rootCmd
+	to           string
+	from         string
+	smtpHost     string
+	smtpPort     int
+	smtpUser     string
+	smtpPassword string
+	title        string
+	file         string
+	location     string
+	tag          string
+	limit        int
+	flags        *ff.FlagSet
+	command      *ff.Command
+}
+
+func newSendCmd(root *rootCmd) *sendCmd {
+	cmd := &sendCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("send").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.to, 0, "to", "", "address to mail the book to, e.g. a Send to Kindle address")
+	cmd.flags.StringVar(&cmd.from, 0, "from", "", "sender address, defaults to --smtp-user")
+	cmd.flags.StringVar(&cmd.smtpHost, 0, "smtp-host", "", "SMTP server")
+	cmd.flags.IntVar(&cmd.smtpPort, 0, "smtp-port", 587, "SMTP port, 465 for implicit TLS")
+	cmd.flags.StringVar(&cmd.smtpUser, 0, "smtp-user", "", "SMTP user name")
+	cmd.flags.StringVar(&cmd.smtpPassword, 0, "smtp-password", "", "SMTP password")
+	cmd.flags.StringVar(&cmd.title, 0, "title", "", "book title, defaults to the document title or the date")
+	cmd.flags.StringVar(&cmd.file, 0, "file", "", "write the EPUB to this file instead of mailing it")
+	cmd.flags.StringVar(&cmd.location, 0, "location", "", "without IDs, send cached documents in this location")
+	cmd.flags.StringVar(&cmd.tag, 0, "tag", "", "without IDs, send cached documents with this tag")
+	cmd.flags.IntVar(&cmd.limit, 'n', "limit", 10, "without IDs, send at most this many documents, most recent first")
+	cmd.command = &ff.Command{
+		Name:      "send",
+		Usage:     "readerctl send [FLAGS] [<ID> ...]",
+		ShortHelp: "mail documents as an EPUB, e.g. to an ereader",
+		LongHelp: "Documents are given by ID, or selected from the local cache with " +
+			"--location, --tag and --limit. Put the SMTP settings in the config file.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *sendCmd) exec(ctx context.Context, args []string) error {
+	if c.file == "" && (c.to == "" || c.smtpHost == "") {
+		return errors.New("--to and --smtp-host are required, or --file")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	docs, err := c.selectDocuments(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return errors.New("no documents to send")
+	}
+
+	book, err := c.book(ctx, client, docs)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := book.Write(&buf); err != nil {
+		return err
+	}
+
+	if c.file != "" {
+		return os.WriteFile(c.file, buf.Bytes(), 0o644)
+	}
+
+	name := documentFileName(time.Now().Format("20060102"), book.Title, ".epub")
+	if err := c.mail(book.Title, name, buf.Bytes()); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	fmt.Fprintf(c.stderr, "sent %d documents to %s\n", len(book.Chapters), c.to)
+	return nil
+}
+
+func (c *sendCmd) selectDocuments(ctx context.Context, ids []string) ([]readwisereader.Document, error) {
+	if len(ids) > 0 {
+		docs := make([]readwisereader.Document, 0, len(ids))
+		for _, id := range ids {
+			doc, err := c.lookupDocument(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, *doc)
+		}
+		return docs, nil
+	}
+
+	var location readwisereader.Location
+	if c.location != "" {
+		var err error
+		if location, err = parseLocation(c.location); err != nil {
+			return nil, err
+		}
+	}
+
+	var docs []readwisereader.Document
+	for doc, err := range c.cachedDocuments(ctx) {
+		if err != nil {
+			return nil, err
+		}
+
+		if doc.ParentID != "" || (location != "" && doc.Location != location) {
+			continue
+		}
+
+		if _, ok := doc.Tags[c.tag]; c.tag != "" && !ok {
+			continue
+		}
+
+		docs = append(docs, doc)
+		if c.limit > 0 && len(docs) == c.limit {
+			break
+		}
+	}
+
+	return docs, nil
+}
+
+// book fetches the content of docs, keeping their order. Documents without
+// usable content are left out with a warning.
+func (c *sendCmd) book(ctx context.Context, client *readwisereader.Client, docs []readwisereader.Document) (*epub.Book, error) {
+	fetcher := contentfetch.Fetcher{API: client}
+	content := map[string]string{}
+	err := fetcher.Fetch(ctx, documentSeq(docs), func(r contentfetch.Result) error {
+		if r.Err != nil {
+			fmt.Fprintf(c.stderr, "skipping %s: %v\n", r.Document.ID, r.Err)
+			return nil
+		}
+
+		body := string(r.Body)
+		if r.Origin == contentfetch.OriginSource {
+			base, _ := url.Parse(r.URL)
+			extracted, err := readability.Extract(bytes.NewReader(r.Body), base)
+			if err != nil {
+				fmt.Fprintf(c.stderr, "skipping %s: %v\n", r.Document.ID, err)
+				return nil
+			}
+			body = extracted
+		}
+
+		content[r.Document.ID] = body
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	book := &epub.Book{Title: c.title}
+	for _, doc := range docs {
+		if body, ok := content[doc.ID]; ok {
+			book.Chapters = append(book.Chapters, epub.Chapter{Title: doc.Title, Author: doc.Author, HTML: body})
+		}
+	}
+
+	if len(book.Chapters) == 0 {
+		return nil, errors.New("none of the documents has content")
+	}
+
+	if book.Title == "" {
+		if len(book.Chapters) == 1 {
+			book.Title = book.Chapters[0].Title
+			book.Author = book.Chapters[0].Author
+		} else {
+			book.Title = "Reader " + time.Now().Format(time.DateOnly)
+		}
+	}
+
+	return book, nil
+}
+
+func (c *sendCmd) mail(subject, filename string, attachment []byte) error {
+	from := c.from
+	if from == "" {
+		from = c.smtpUser
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	fmt.Fprintf(&body, "From: %s\r\n", from)
+	fmt.Fprintf(&body, "To: %s\r\n", c.to)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&body, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&body, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(text, "%s, sent by readerctl.\r\n", subject)
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType("application/epub+zip", map[string]string{"name": filename})},
+		"Content-Disposition":       {disposition},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment)
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(part, "%s\r\n", encoded)
+
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(c.smtpHost, strconv.Itoa(c.smtpPort))
+	var auth smtp.Auth
+	if c.smtpUser != "" {
+		auth = smtp.PlainAuth("", c.smtpUser, c.smtpPassword, c.smtpHost)
+	}
+
+	if c.smtpPort != 465 {
+		// SendMail upgrades with STARTTLS when the server offers it.
+		return smtp.SendMail(addr, auth, from, []string{c.to}, body.Bytes())
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: c.smtpHost})
+	if err != nil {
+		return err
+	}
+
+	sc, err := smtp.NewClient(conn, c.smtpHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer sc.Close()
+
+	if auth != nil {
+		if err := sc.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := sc.Mail(from); err != nil {
+		return err
+	}
+	if err := sc.Rcpt(c.to); err != nil {
+		return err
+	}
+
+	w, err := sc.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return sc.Quit()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type shareCmd struct {
+	*rootCmd
+	format  string
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newShareCmd(root *rootCmd) *shareCmd {
+	cmd := &shareCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("share").SetParent(root.flags)
+	cmd.flags.StringEnumVar(&cmd.format, 0, "format", "snippet format", "md", "html")
+	cmd.command = &ff.Command{
+		Name:      "share",
+		Usage:     "readerctl share [FLAGS] <ID>",
+		ShortHelp: "format a document with its highlights for sharing",
+		LongHelp:  "Highlights are read from the local cache, run readerctl sync first.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *shareCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one document ID")
+	}
+
+	doc, err := c.lookupDocument(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	highlights, err := c.cachedChildren(ctx, doc.ID)
+	if err != nil {
+		return err
+	}
+
+	if c.format == "html" {
+		return writeShareHTML(c.stdout, *doc, highlights)
+	}
+
+	return writeShareMarkdown(c.stdout, *doc, highlights)
+}
+
+func writeShareMarkdown(w io.Writer, doc readwisereader.Document, highlights []readwisereader.Document) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "**[%s](%s)**", doc.Title, documentLink(doc))
+	if doc.Author != "" {
+		fmt.Fprintf(&b, " by %s", doc.Author)
+	}
+	b.WriteString("\n")
+
+	for _, h := range highlights {
+		b.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimSpace(h.Content), "\n") {
+			fmt.Fprintf(&b, "> %s\n", line)
+		}
+		if h.Notes != "" {
+			fmt.Fprintf(&b, "\n_%s_\n", strings.TrimSpace(h.Notes))
+		}
+	}
+
+	if doc.Notes != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(doc.Notes))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeShareHTML(w io.Writer, doc readwisereader.Document, highlights []readwisereader.Document) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<p><a href=\"%s\"><strong>%s</strong></a>", html.EscapeString(documentLink(doc)), html.EscapeString(doc.Title))
+	if doc.Author != "" {
+		fmt.Fprintf(&b, " by %s", html.EscapeString(doc.Author))
+	}
+	b.WriteString("</p>\n")
+
+	for _, h := range highlights {
+		fmt.Fprintf(&b, "<blockquote>%s</blockquote>\n", html.EscapeString(strings.TrimSpace(h.Content)))
+		if h.Notes != "" {
+			fmt.Fprintf(&b, "<p><em>%s</em></p>\n", html.EscapeString(strings.TrimSpace(h.Notes)))
+		}
+	}
+
+	if doc.Notes != "" {
+		fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(strings.TrimSpace(doc.Notes)))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
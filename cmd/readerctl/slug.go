@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength keeps file names derived from titles manageable.
+const maxSlugLength = 60
+
+// slugify turns a title into a lowercase, dash separated name safe for file
+// names.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(truncateRunes(slug, maxSlugLength), "-")
+	}
+
+	return slug
+}
+
+// documentFileName names a file for a document by ID and title slug.
+func documentFileName(id, title, ext string) string {
+	if slug := slugify(title); slug != "" {
+		return id + "-" + slug + ext
+	}
+
+	return id + ext
+}
+
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+
+	return s
+}
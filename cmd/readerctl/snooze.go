@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type snooze struct {
+	ID    string    `json:"id"`
+	Title string    `json:"title"`
+	Until time.Time `json:"until"`
+}
+
+func (r *rootCmd) loadSnoozes() ([]snooze, error) {
+	var snoozes []snooze
+	err := readJSONFile(r.stateFile("snoozes.json"), &snoozes)
+	return snoozes, err
+}
+
+func (r *rootCmd) saveSnoozes(snoozes []snooze) error {
+	return writeJSONFile(r.stateFile("snoozes.json"), snoozes)
+}
+
+// resurfaceSnoozes moves documents whose snooze expired back to New.
+func (r *rootCmd) resurfaceSnoozes(ctx context.Context, client *readwisereader.Client) error {
+	snoozes, err := r.loadSnoozes()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	pending := snoozes[:0]
+	for _, s := range snoozes {
+		if s.Until.After(now) {
+			pending = append(pending, s)
+			continue
+		}
+
+		_, err := client.Update(ctx, s.ID, readwisereader.UpdateParams{
+			Location: readwisereader.LocationNew,
+		})
+		if err != nil {
+			// Keep it around, the next run will try again.
+			pending = append(pending, s)
+			fmt.Fprintf(r.stderr, "resurface %s: %v\n", s.ID, err)
+			continue
+		}
+
+		fmt.Fprintf(r.stderr, "resurfaced %s %s\n", s.ID, s.Title)
+	}
+
+	return r.saveSnoozes(pending)
+}
+
+type snoozeCmd struct {
+	*rootCmd
+	duration string
+	flags    *ff.FlagSet
+	command  *ff.Command
+}
+
+func newSnoozeCmd(root *rootCmd) *snoozeCmd {
+	cmd := &snoozeCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("snooze").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.duration, 0, "for", "1w", "how long to snooze for, e.g. 3d or 2w")
+	cmd.command = &ff.Command{
+		Name:      "snooze",
+		Usage:     "readerctl snooze [FLAGS] <ID>",
+		ShortHelp: "move a document to Later until a given time",
+		LongHelp: `The document is moved back to New by readerctl sync once the snooze
+expires.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	newSnoozeListCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *snoozeCmd) exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one document ID")
+	}
+
+	d, err := parseDuration(c.duration)
+	if err != nil {
+		return fmt.Errorf("--for: %w", err)
+	}
+
+	doc, err := c.lookupDocument(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Update(ctx, doc.ID, readwisereader.UpdateParams{
+		Location: readwisereader.LocationLater,
+	})
+	if err != nil {
+		return err
+	}
+
+	snoozes, err := c.loadSnoozes()
+	if err != nil {
+		return err
+	}
+
+	snoozes = slices.DeleteFunc(snoozes, func(s snooze) bool { return s.ID == doc.ID })
+	snoozes = append(snoozes, snooze{
+		ID:    doc.ID,
+		Title: doc.Title,
+		Until: time.Now().Add(d),
+	})
+
+	return c.saveSnoozes(snoozes)
+}
+
+type snoozeListCmd struct {
+	*snoozeCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newSnoozeListCmd(parent *snoozeCmd) *snoozeListCmd {
+	cmd := &snoozeListCmd{snoozeCmd: parent}
+	cmd.flags = ff.NewFlagSet("list").SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      "list",
+		Usage:     "readerctl snooze list [FLAGS]",
+		ShortHelp: "list snoozed documents",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *snoozeListCmd) exec(ctx context.Context, args []string) error {
+	snoozes, err := c.loadSnoozes()
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(snoozes, func(a, b snooze) int {
+		return a.Until.Compare(b.Until)
+	})
+
+	switch c.output {
+	case outputJSON:
+		return writeJSON(c.stdout, snoozes)
+	case outputTable, outputCSV, outputTSV:
+	default:
+		return unsupportedOutput(c.output)
+	}
+
+	t := newTable("ID", "TITLE", "UNTIL")
+	for _, s := range snoozes {
+		t.add(s.ID, s.Title, s.Until.Local().Format(time.DateTime))
+	}
+
+	return c.writeTable(t)
+}
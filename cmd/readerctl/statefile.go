@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stateFile returns the path of a readerctl managed file kept next to the
+// config file, in a directory of its own for a profile.
+func (r *rootCmd) stateFile(name string) string {
+	if r.profile != "" {
+		return filepath.Join(filepath.Dir(r.config), "profiles", r.profile, name)
+	}
+
+	return filepath.Join(filepath.Dir(r.config), name)
+}
+
+// readJSONFile decodes path into v, leaving v untouched if the file doesn't
+// exist yet.
+func readJSONFile(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("decode %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+func writeJSONFile(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o600)
+}
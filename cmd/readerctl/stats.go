@@ -0,0 +1,236 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// completedProgress is the reading progress at which a document counts as
+// read; hardly anyone scrolls to the very last pixel.
+const completedProgress = 0.9
+
+type statsCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newStatsCmd(root *rootCmd) *statsCmd {
+	cmd := &statsCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("stats").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "stats",
+		Usage:     "readerctl stats <SUBCOMMAND> ...",
+		ShortHelp: "reading statistics computed from the local cache",
+		Flags:     cmd.flags,
+	}
+
+	newStatsTopCmd(cmd)
+	newStatsReadingCmd(cmd)
+	newStatsStreakCmd(cmd)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type statsTopCmd struct {
+	*statsCmd
+	authors bool
+	sites   bool
+	since   timeValue
+	limit   int
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newStatsTopCmd(parent *statsCmd) *statsTopCmd {
+	cmd := &statsTopCmd{statsCmd: parent}
+	cmd.flags = ff.NewFlagSet("top").SetParent(parent.flags)
+	cmd.flags.BoolVar(&cmd.authors, 0, "authors", "group by author")
+	cmd.flags.BoolVar(&cmd.sites, 0, "sites", "group by site")
+	cmd.flags.Value(0, "since", &cmd.since, "only consider documents saved after this time, e.g. 90d or monday")
+	cmd.flags.IntVar(&cmd.limit, 'n', "limit", 10, "number of rows to show")
+	cmd.command = &ff.Command{
+		Name:      "top",
+		Usage:     "readerctl stats top --authors|--sites [FLAGS]",
+		ShortHelp: "authors or sites read the most",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+type topEntry struct {
+	name      string
+	documents int
+	words     int
+	completed int
+}
+
+func (c *statsTopCmd) exec(ctx context.Context, args []string) error {
+	if !isTabular(c.output) {
+		return unsupportedOutput(c.output)
+	}
+
+	if c.authors == c.sites {
+		return errors.New("exactly one of --authors or --sites is required")
+	}
+
+	savedAfter := c.since.Time
+
+	docs, err := c.store().Documents(ctx)
+	if err != nil {
+		return err
+	}
+
+	key := documentAuthor
+	if c.sites {
+		key = documentSite
+	}
+
+	entries := map[string]*topEntry{}
+	for _, doc := range docs {
+		// Highlights and notes are children of the documents they belong
+		// to, counting them would inflate the numbers.
+		if doc.ParentID != "" {
+			continue
+		}
+
+		if doc.SavedAt.Before(savedAfter) {
+			continue
+		}
+
+		name := key(doc)
+		if name == "" {
+			continue
+		}
+
+		e, ok := entries[name]
+		if !ok {
+			e = &topEntry{name: name}
+			entries[name] = e
+		}
+
+		e.documents++
+		e.words += doc.WordCount
+		if doc.ReadingProgress >= completedProgress {
+			e.completed++
+		}
+	}
+
+	top := make([]*topEntry, 0, len(entries))
+	for _, e := range entries {
+		top = append(top, e)
+	}
+
+	slices.SortFunc(top, func(a, b *topEntry) int {
+		return cmp.Or(
+			cmp.Compare(b.documents, a.documents),
+			cmp.Compare(b.words, a.words),
+			strings.Compare(a.name, b.name),
+		)
+	})
+
+	if c.limit > 0 && len(top) > c.limit {
+		top = top[:c.limit]
+	}
+
+	header := "AUTHOR"
+	if c.sites {
+		header = "SITE"
+	}
+
+	t := newTable(header, "DOCUMENTS", "WORDS", "COMPLETED")
+	for _, e := range top {
+		rate := float64(e.completed) / float64(e.documents) * 100
+		t.add(e.name, strconv.Itoa(e.documents), strconv.Itoa(e.words), fmt.Sprintf("%.0f%%", rate))
+	}
+
+	return c.writeTable(t)
+}
+
+func documentAuthor(doc readwisereader.Document) string {
+	return strings.TrimSpace(doc.Author)
+}
+
+func documentSite(doc readwisereader.Document) string {
+	if doc.SiteName != "" {
+		return doc.SiteName
+	}
+
+	u, err := url.Parse(doc.SourceURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+type statsReadingCmd struct {
+	*statsCmd
+	since   timeValue
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newStatsReadingCmd(parent *statsCmd) *statsReadingCmd {
+	cmd := &statsReadingCmd{statsCmd: parent}
+	cmd.flags = ff.NewFlagSet("reading").SetParent(parent.flags)
+	cmd.since.Set("7d")
+	cmd.flags.Value(0, "since", &cmd.since, "start of the period to summarize, e.g. 7d or monday")
+	cmd.command = &ff.Command{
+		Name:      "reading",
+		Usage:     "readerctl stats reading [FLAGS]",
+		ShortHelp: "words read and documents finished, from the sync history",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *statsReadingCmd) exec(ctx context.Context, args []string) error {
+	transitions, err := c.store().Transitions(ctx, c.since.Time)
+	if err != nil {
+		return err
+	}
+
+	var words, archived int
+	documents := map[string]bool{}
+	for _, t := range transitions {
+		if n := t.WordsRead(); n > 0 {
+			words += n
+			documents[t.DocumentID] = true
+		}
+
+		if t.ToLocation == readwisereader.LocationArchive && t.FromLocation != readwisereader.LocationArchive {
+			archived++
+		}
+	}
+
+	switch c.output {
+	case outputJSON:
+		return writeJSON(c.stdout, map[string]any{
+			"words":     words,
+			"documents": len(documents),
+			"archived":  archived,
+		})
+	case outputTable:
+		fmt.Fprintf(c.stdout, "read %d words across %d documents, archived %d\n", words, len(documents), archived)
+		return nil
+	default:
+		return unsupportedOutput(c.output)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type statsStreakCmd struct {
+	*statsCmd
+	weeks   int
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newStatsStreakCmd(parent *statsCmd) *statsStreakCmd {
+	cmd := &statsStreakCmd{statsCmd: parent}
+	cmd.flags = ff.NewFlagSet("streak").SetParent(parent.flags)
+	cmd.flags.IntVar(&cmd.weeks, 0, "weeks", 26, "number of weeks shown in the heatmap")
+	cmd.command = &ff.Command{
+		Name:      "streak",
+		Usage:     "readerctl stats streak [FLAGS]",
+		ShortHelp: "consecutive days with reading activity, from the sync history",
+		LongHelp: "A day counts as active if a document was read further or archived on it. " +
+			"The heatmap shows one column per week, Monday on top.",
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *statsStreakCmd) exec(ctx context.Context, args []string) error {
+	if c.output != outputTable && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	// Streaks can go back further than the heatmap, take the whole history.
+	transitions, err := c.store().Transitions(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	activity := map[string]int{}
+	for _, t := range transitions {
+		archived := t.ToLocation == readwisereader.LocationArchive && t.FromLocation != readwisereader.LocationArchive
+		if t.WordsRead() > 0 || archived {
+			activity[t.Day]++
+		}
+	}
+
+	today := time.Now()
+	current, longest := streaks(activity, today)
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, map[string]any{
+			"current": current,
+			"longest": longest,
+			"days":    activity,
+		})
+	}
+
+	fmt.Fprintf(c.stdout, "current streak: %s, longest: %s\n\n", pluralDays(current), pluralDays(longest))
+	fmt.Fprint(c.stdout, heatmap(activity, today, c.weeks))
+	return nil
+}
+
+// streaks returns the streak running up to today, which is not broken yet if
+// only today is missing, and the longest streak on record.
+func streaks(activity map[string]int, today time.Time) (current, longest int) {
+	day := func(t time.Time) string { return t.Format(time.DateOnly) }
+
+	start := today
+	if activity[day(start)] == 0 {
+		start = start.AddDate(0, 0, -1)
+	}
+	for d := start; activity[day(d)] > 0; d = d.AddDate(0, 0, -1) {
+		current++
+	}
+
+	for key := range activity {
+		t, err := time.ParseInLocation(time.DateOnly, key, today.Location())
+		if err != nil {
+			continue
+		}
+
+		// Only count from the first day of each streak.
+		if activity[day(t.AddDate(0, 0, -1))] > 0 {
+			continue
+		}
+
+		n := 0
+		for d := t; activity[day(d)] > 0; d = d.AddDate(0, 0, 1) {
+			n++
+		}
+		longest = max(longest, n)
+	}
+
+	return current, longest
+}
+
+// heatmap renders activity as a grid of weeks by weekdays, ending with the
+// week of today.
+func heatmap(activity map[string]int, today time.Time, weeks int) string {
+	weeks = max(weeks, 1)
+	first := startOfWeek(today).AddDate(0, 0, -7*(weeks-1))
+
+	var b strings.Builder
+
+	// Month labels above the first week starting in each month.
+	b.WriteString("    ")
+	for w := 0; w < weeks; {
+		monday := first.AddDate(0, 0, 7*w)
+		if w == 0 || monday.Day() <= 7 {
+			label := monday.Format("Jan")
+			if w+len(label) <= weeks {
+				b.WriteString(label)
+				w += len(label)
+				continue
+			}
+		}
+		b.WriteByte(' ')
+		w++
+	}
+	b.WriteByte('\n')
+
+	for weekday := range 7 {
+		label := "   "
+		if weekday%2 == 0 {
+			label = first.AddDate(0, 0, weekday).Format("Mon")
+		}
+		b.WriteString(label + " ")
+
+		for w := range weeks {
+			d := first.AddDate(0, 0, 7*w+weekday)
+			if d.After(today) {
+				b.WriteByte(' ')
+				continue
+			}
+			b.WriteString(heatLevel(activity[d.Format(time.DateOnly)]))
+		}
+		b.WriteByte('\n')
+	}
+
+	b.WriteString("\nless · ░ ▒ ▓ █ more\n")
+	return b.String()
+}
+
+func heatLevel(n int) string {
+	switch {
+	case n == 0:
+		return "·"
+	case n == 1:
+		return "░"
+	case n <= 3:
+		return "▒"
+	case n <= 5:
+		return "▓"
+	default:
+		return "█"
+	}
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
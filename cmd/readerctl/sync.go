@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.selman.me/go-readwisereader/sync"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type syncCmd struct {
+	*rootCmd
+	reconcile      bool
+	reconcileEvery time.Duration
+	trackContent   bool
+	diff           bool
+	sqlite         bool
+	withHTML       bool
+
+	updatedAfter timeValue
+
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newSyncCmd(root *rootCmd) *syncCmd {
+	cmd := &syncCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("sync").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.reconcile, 0, "reconcile", "drop cached documents deleted in Reader, lists the whole library")
+	cmd.flags.DurationVar(&cmd.reconcileEvery, 0, "reconcile-every", 0, "reconcile when the last reconciliation is older than this, 0 to only reconcile on --reconcile")
+	cmd.flags.BoolVar(&cmd.trackContent, 0, "track-content", "fetch html content and report documents whose content changed since the last sync")
+	cmd.flags.BoolVar(&cmd.diff, 0, "diff", "print the text that changed, with --track-content")
+	cmd.flags.BoolVar(&cmd.sqlite, 0, "sqlite", "also update the local SQLite mirror that readerctl search reads")
+	cmd.flags.BoolVar(&cmd.withHTML, 0, "with-html", "store html content in the SQLite mirror, so search can look through it")
+	cmd.flags.Value(0, "updated-after", &cmd.updatedAfter, "also fetch documents updated after this time again, e.g. 7d, yesterday or 2024-05-01; a time after the last sync is ignored so nothing is skipped")
+	cmd.command = &ff.Command{
+		Name:      "sync",
+		Usage:     "readerctl sync [FLAGS]",
+		ShortHelp: "update the local document cache",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *syncCmd) exec(ctx context.Context, args []string) error {
+	if c.withHTML && !c.sqlite {
+		return &usageError{err: errors.New("--with-html requires --sqlite")}
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	reconcileEvery := c.reconcileEvery
+	if c.reconcile {
+		// Reconciled unconditionally below.
+		reconcileEvery = 0
+	}
+
+	opts := []sync.Option{sync.WithReconcileInterval(reconcileEvery), sync.WithUpdatedAfter(c.updatedAfter.Time)}
+	if c.trackContent {
+		opts = append(opts, sync.WithContentTracking())
+	}
+
+	var store sync.Store = c.store()
+	if c.sqlite {
+		mirrored, err := c.openMirrored(ctx, c.trackContent)
+		if err != nil {
+			return err
+		}
+		defer mirrored.Close()
+
+		store = mirrored
+		if c.withHTML {
+			opts = append(opts, sync.WithHTMLContent())
+		}
+	}
+
+	syncer := sync.New(client, store, opts...)
+	syncer.Subscribe(func(ctx context.Context, event sync.Event) {
+		if e, ok := event.(sync.ContentChanged); ok {
+			c.reportContentChange(e)
+		}
+	})
+
+	script, err := c.loadScript(ctx, client)
+	if err != nil {
+		return err
+	}
+	script.subscribe(syncer, c.reportScriptError)
+	result, err := syncer.Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	if c.reconcile {
+		result.Deleted, err = syncer.Reconcile(ctx)
+		if err != nil {
+			return fmt.Errorf("reconcile: %w", err)
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "synced %d documents\n", result.Documents)
+	if result.Deleted > 0 {
+		fmt.Fprintf(c.stderr, "removed %d deleted documents\n", result.Deleted)
+	}
+
+	return c.resurfaceSnoozes(ctx, client)
+}
+
+func (c *syncCmd) reportContentChange(e sync.ContentChanged) {
+	fmt.Fprintf(c.stderr, "content changed: %s (%s)\n", e.Document.Title, e.Document.ID)
+	if !c.diff {
+		return
+	}
+
+	if e.Previous.HTMLContent == "" {
+		fmt.Fprintf(c.stderr, "no earlier content of %s to diff against\n", e.Document.ID)
+		return
+	}
+
+	fmt.Fprintf(c.stdout, "--- %s %s\n", e.Document.ID, e.Document.Title)
+	if !writeLineDiff(c.stdout, blockTexts(e.Previous.HTMLContent), blockTexts(e.Document.HTMLContent)) {
+		fmt.Fprintf(c.stderr, "%s is too long to diff\n", e.Document.ID)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/peterbourgon/ff/v4/ffhelp"
+)
+
+const (
+	tablePadding  = 2
+	minColumnSize = 5
+)
+
+// tableOptions controls how tables fit into narrow terminals.
+type tableOptions struct {
+	maxWidth     int
+	truncate     bool
+	wrap         bool
+	columnWidths []string
+}
+
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func newTable(headers ...string) *table {
+	return &table{headers: headers}
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func (r *rootCmd) writeTable(t *table) error {
+	switch r.output {
+	case outputCSV:
+		w := csv.NewWriter(r.stdout)
+		w.WriteAll(append([][]string{t.headers}, t.rows...))
+		return w.Error()
+	case outputTSV:
+		return writeTSV(r.stdout, t)
+	}
+
+	widths, err := r.table.widths(t)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		writeTableRow(&b, row, widths, r.table.wrap)
+	}
+
+	_, err = io.WriteString(r.stdout, b.String())
+	return err
+}
+
+// writeTSV writes t as tab separated rows. Tabs and line breaks within cells
+// become spaces, TSV has no way to quote them.
+func writeTSV(w io.Writer, t *table) error {
+	clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+	var b strings.Builder
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+			b.WriteString(clean.Replace(cell))
+		}
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// widths computes the width of every column: the widest cell, capped by any
+// --column-width, then shrunk widest first until the table fits --max-width.
+func (o tableOptions) widths(t *table) ([]int, error) {
+	widths := make([]int, len(t.headers))
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	for _, spec := range o.columnWidths {
+		name, value, ok := strings.Cut(spec, "=")
+		n, err := strconv.Atoi(value)
+		if !ok || err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid column width %q, expected NAME=WIDTH", spec)
+		}
+
+		for i, header := range t.headers {
+			if strings.EqualFold(header, name) {
+				widths[i] = min(widths[i], n)
+			}
+		}
+	}
+
+	maxWidth := o.maxWidth
+	if maxWidth == 0 && (o.truncate || o.wrap) {
+		maxWidth = terminalWidth()
+	}
+
+	if maxWidth <= 0 {
+		return widths, nil
+	}
+
+	total := func() int {
+		n := tablePadding * (len(widths) - 1)
+		for _, w := range widths {
+			n += w
+		}
+		return n
+	}
+
+	for total() > maxWidth {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+
+		if widths[widest] <= minColumnSize {
+			break
+		}
+
+		widths[widest]--
+	}
+
+	return widths, nil
+}
+
+func writeTableRow(b *strings.Builder, row []string, widths []int, wrap bool) {
+	cells := make([][]string, len(row))
+	height := 1
+	for i, cell := range row {
+		if wrap {
+			cells[i] = wrapText(cell, widths[i])
+		} else {
+			cells[i] = []string{truncate(cell, widths[i])}
+		}
+		height = max(height, len(cells[i]))
+	}
+
+	for line := range height {
+		var sb strings.Builder
+		for i, cell := range cells {
+			var text string
+			if line < len(cell) {
+				text = cell[line]
+			}
+
+			sb.WriteString(text)
+			if i < len(cells)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(text)+tablePadding))
+			}
+		}
+
+		b.WriteString(strings.TrimRight(sb.String(), " "))
+		b.WriteString("\n")
+	}
+}
+
+// wrapText breaks s into lines of at most width runes, on word boundaries
+// where possible.
+func wrapText(s string, width int) []string {
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(s) {
+		w := []rune(word)
+		if len(line) > 0 && len(line)+1+len(w) > width {
+			lines = append(lines, string(line))
+			line = nil
+		}
+
+		for len(w) > width {
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, w...)
+	}
+
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, string(line))
+	}
+
+	return lines
+}
+
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+
+	return ffhelp.Columns()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type tagCmd struct {
+	*rootCmd
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newTagCmd(root *rootCmd) *tagCmd {
+	cmd := &tagCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("tag").SetParent(root.flags)
+	cmd.command = &ff.Command{
+		Name:      "tag",
+		Usage:     "readerctl tag <SUBCOMMAND> ...",
+		ShortHelp: "change the tags of a document",
+		Flags:     cmd.flags,
+	}
+
+	newTagChangeCmd(cmd, "add", "add tags to a document", addTags)
+	newTagChangeCmd(cmd, "rm", "remove tags from a document", removeTags)
+	newTagChangeCmd(cmd, "set", "replace the tags of a document, with none to clear them", setTags)
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type tagChangeCmd struct {
+	*tagCmd
+	// change returns the tags of a document tagged current after the change.
+	change  func(current, tags []string) []string
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newTagChangeCmd(parent *tagCmd, name, help string, change func(current, tags []string) []string) *tagChangeCmd {
+	cmd := &tagChangeCmd{tagCmd: parent, change: change}
+	cmd.flags = ff.NewFlagSet(name).SetParent(parent.flags)
+	cmd.command = &ff.Command{
+		Name:      name,
+		Usage:     fmt.Sprintf("readerctl tag %s <ID> <TAG>...", name),
+		ShortHelp: help,
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	parent.command.Subcommands = append(parent.command.Subcommands, cmd.command)
+	return cmd
+}
+
+func (c *tagChangeCmd) exec(ctx context.Context, args []string) error {
+	if c.output != outputTable && c.output != outputJSON {
+		return unsupportedOutput(c.output)
+	}
+
+	if len(args) < 1 {
+		return &usageError{err: errors.New("expected a document ID and tags")}
+	}
+	id, tags := args[0], args[1:]
+	if len(tags) == 0 && c.command.Name != "set" {
+		return &usageError{err: errors.New("expected at least one tag")}
+	}
+
+	// Reader only takes the whole set of tags, fetch the current ones to
+	// change them.
+	doc, err := c.fetchDocument(ctx, id, false)
+	if err != nil {
+		return err
+	}
+
+	updated := c.change(documentTags(*doc), tags)
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var params readwisereader.UpdateParams
+	params.SetTags(updated...)
+	params.UnmodifiedSince = doc.UpdatedAt
+	if _, err := client.Update(ctx, id, params); err != nil {
+		return fmt.Errorf("tag %s: %w", id, err)
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, map[string]any{
+			"id":   id,
+			"tags": updated,
+		})
+	}
+
+	fmt.Fprintln(c.stdout, strings.Join(updated, ", "))
+	return nil
+}
+
+// documentTags returns the names of the tags on doc, sorted.
+func documentTags(doc readwisereader.Document) []string {
+	tags := make([]string, 0, len(doc.Tags))
+	for _, key := range slices.Sorted(maps.Keys(doc.Tags)) {
+		tags = append(tags, cmp.Or(doc.Tags[key].Name, key))
+	}
+
+	return tags
+}
+
+func addTags(current, tags []string) []string {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(current, sameTag(tag)) {
+			current = append(current, tag)
+		}
+	}
+
+	return current
+}
+
+func removeTags(current, tags []string) []string {
+	return slices.DeleteFunc(current, func(tag string) bool {
+		return slices.ContainsFunc(tags, sameTag(tag))
+	})
+}
+
+func setTags(current, tags []string) []string {
+	return tags
+}
+
+// sameTag matches tags by name, ignoring case like Reader does.
+func sameTag(tag string) func(string) bool {
+	return func(other string) bool {
+		return strings.EqualFold(strings.TrimSpace(tag), strings.TrimSpace(other))
+	}
+}
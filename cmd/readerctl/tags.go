@@ -0,0 +1,201 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// cloudStyles are the ANSI styles of the tag cloud weights, lightest first.
+var cloudStyles = []string{"\x1b[2m", "", "\x1b[1m", "\x1b[1;4m", "\x1b[1;7m"}
+
+type tagsCmd struct {
+	*rootCmd
+	cloud   bool
+	json    bool
+	remote  bool
+	flags   *ff.FlagSet
+	command *ff.Command
+}
+
+func newTagsCmd(root *rootCmd) *tagsCmd {
+	cmd := &tagsCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("tags").SetParent(root.flags)
+	cmd.flags.BoolVar(&cmd.cloud, 0, "cloud", "render a tag cloud weighted by use")
+	cmd.flags.BoolVar(&cmd.remote, 0, "remote", "count tags over a full listing from the API instead of the local cache")
+	cmd.flags.BoolVar(&cmd.json, 0, "json", "write every tag with its counts and weight as JSON, same as --output json")
+	cmd.command = &ff.Command{
+		Name:      "tags",
+		Usage:     "readerctl tags [FLAGS]",
+		ShortHelp: "list tags by how often they are used",
+		LongHelp:  "Tags are counted in the local cache, run readerctl sync first or pass --remote.",
+		Flags:     cmd.flags,
+		Exec:      cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+type tagCount struct {
+	Tag        string `json:"tag"`
+	Count      int    `json:"count"`
+	Documents  int    `json:"documents"`
+	Highlights int    `json:"highlights"`
+	// Weight on a logarithmic scale, 1 for the most used tag
+	Weight float64 `json:"weight"`
+}
+
+func (c *tagsCmd) exec(ctx context.Context, args []string) error {
+	output := c.output
+	if c.json {
+		output = outputJSON
+	}
+
+	if !isTabular(output) && output != outputJSON {
+		return unsupportedOutput(output)
+	}
+
+	docs := c.cachedDocuments(ctx)
+	if c.remote {
+		client, err := c.client()
+		if err != nil {
+			return err
+		}
+		docs = client.Documents(ctx, readwisereader.ListParams{})
+	}
+
+	tags, err := tagCounts(docs)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case output == outputJSON:
+		return writeJSON(c.stdout, tags)
+	case c.cloud:
+		fmt.Fprint(c.stdout, tagCloud(tags, terminalWidth(), useColor(c.stdout)))
+		return nil
+	}
+
+	t := newTable("TAG", "DOCUMENTS", "HIGHLIGHTS")
+	for _, tag := range tags {
+		t.add(tag.Tag, strconv.Itoa(tag.Documents), strconv.Itoa(tag.Highlights))
+	}
+
+	return c.writeTable(t)
+}
+
+// tagCounts counts the tags on docs, most used first.
+func tagCounts(docs iter.Seq2[readwisereader.Document, error]) ([]tagCount, error) {
+	counts := map[string]*tagCount{}
+	for doc, err := range docs {
+		if err != nil {
+			return nil, err
+		}
+
+		for tag := range doc.Tags {
+			tc := counts[tag]
+			if tc == nil {
+				tc = &tagCount{Tag: tag}
+				counts[tag] = tc
+			}
+
+			tc.Count++
+			if doc.ParentID == "" {
+				tc.Documents++
+			} else {
+				tc.Highlights++
+			}
+		}
+	}
+
+	tags := make([]tagCount, 0, len(counts))
+	var most int
+	for _, tc := range counts {
+		tags = append(tags, *tc)
+		most = max(most, tc.Count)
+	}
+
+	for i := range tags {
+		// Log scale, or a few popular tags flatten everything else.
+		tags[i].Weight = math.Log1p(float64(tags[i].Count)) / math.Log1p(float64(most))
+	}
+
+	slices.SortFunc(tags, func(a, b tagCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Tag, b.Tag))
+	})
+
+	return tags, nil
+}
+
+// tagCloud lays tags out alphabetically in lines of at most width columns.
+// Heavier tags are styled more prominently with color, without it their
+// counts are shown instead.
+func tagCloud(tags []tagCount, width int, color bool) string {
+	tags = slices.Clone(tags)
+	slices.SortFunc(tags, func(a, b tagCount) int {
+		return strings.Compare(a.Tag, b.Tag)
+	})
+
+	var b strings.Builder
+	var col int
+	for _, tag := range tags {
+		word := tag.Tag
+		if !color {
+			word = fmt.Sprintf("%s(%d)", tag.Tag, tag.Count)
+		}
+
+		n := utf8.RuneCountInString(word)
+		if col > 0 && width > 0 && col+1+n > width {
+			b.WriteByte('\n')
+			col = 0
+		}
+		if col > 0 {
+			b.WriteByte(' ')
+			col++
+		}
+
+		if color {
+			level := min(int(tag.Weight*float64(len(cloudStyles))), len(cloudStyles)-1)
+			if style := cloudStyles[level]; style != "" {
+				word = style + word + "\x1b[0m"
+			}
+		}
+
+		b.WriteString(word)
+		col += n
+	}
+
+	if col > 0 {
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// useColor reports whether w is a terminal that should get ANSI styles.
+func useColor(w any) bool {
+	return os.Getenv("NO_COLOR") == "" && isTerminal(w)
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w any) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peterbourgon/ff/v4"
+)
+
+var (
+	tuiPaneStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	tuiFocusedStyle = tuiPaneStyle.BorderForeground(lipgloss.Color("12"))
+)
+
+// tuiMoveKeys are the keys picking a location after m.
+var tuiMoveKeys = map[string]readwisereader.Location{
+	"n": readwisereader.LocationNew,
+	"l": readwisereader.LocationLater,
+	"s": readwisereader.LocationShortList,
+	"a": readwisereader.LocationArchive,
+	"f": readwisereader.LocationFeed,
+}
+
+type tuiCmd struct {
+	*rootCmd
+	location string
+	category string
+	flags    *ff.FlagSet
+	command  *ff.Command
+}
+
+func newTUICmd(root *rootCmd) *tuiCmd {
+	cmd := &tuiCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("tui").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.location, 0, "location", "new", "location to show at first, empty for all")
+	cmd.flags.StringVar(&cmd.category, 0, "category", "", "category to show at first, empty for all")
+	cmd.command = &ff.Command{
+		Name:      "tui",
+		Usage:     "readerctl tui [FLAGS]",
+		ShortHelp: "browse and triage documents in the terminal",
+		LongHelp: `Documents are read from the local cache, run readerctl sync first. Changes
+are sent to Reader right away and written to the cache.
+
+Keys: j/k or ↑/↓ pick a document, tab and shift+tab cycle the location
+shown, c cycles the category, / filters by title, author or site, esc clears
+the filter, a archives, m then n/l/s/a/f moves to new, later, shortlist,
+archive or feed, t adds tags, o opens the document in the browser, d deletes
+it, q quits.`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *tuiCmd) exec(ctx context.Context, args []string) error {
+	m := &tuiModel{ctx: ctx, store: c.store()}
+
+	m.locations = append([]readwisereader.Location{""}, knownLocations...)
+	if c.location != "" {
+		location, err := parseLocation(c.location)
+		if err != nil {
+			return &usageError{err: err}
+		}
+		m.location = slices.Index(m.locations, location)
+	}
+
+	m.categories = append([]readwisereader.Category{""}, knownCategories...)
+	if c.category != "" {
+		category, err := parseCategory(c.category)
+		if err != nil {
+			return &usageError{err: err}
+		}
+		m.category = slices.Index(m.categories, category)
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	m.client = client
+
+	docs, err := m.store.Documents(ctx)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if doc.ParentID == "" {
+			m.docs = append(m.docs, doc)
+		}
+	}
+
+	m.wpm = c.wpm
+	_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx), tea.WithInput(c.stdin), tea.WithOutput(c.stdout)).Run()
+	return err
+}
+
+// tuiMode is what keys are currently taken as.
+type tuiMode int
+
+const (
+	tuiBrowsing tuiMode = iota
+	tuiFiltering
+	tuiMoving
+	tuiTagging
+	tuiConfirmDelete
+)
+
+type tuiModel struct {
+	ctx    context.Context
+	client *readwisereader.Client
+	store  *sync.FileStore
+	wpm    int
+
+	docs       []readwisereader.Document
+	locations  []readwisereader.Location
+	location   int
+	categories []readwisereader.Category
+	category   int
+	filter     string
+
+	cursor int
+	mode   tuiMode
+	input  string
+
+	width, height int
+	status        string
+}
+
+// tuiUpdated reports the outcome of changing a document in Reader.
+type tuiUpdated struct {
+	doc    readwisereader.Document
+	action string
+	err    error
+}
+
+// tuiDeleted reports the outcome of deleting a document in Reader.
+type tuiDeleted struct {
+	doc readwisereader.Document
+	err error
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tuiUpdated:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("%s %q failed: %v", msg.action, msg.doc.Title, msg.err)
+			break
+		}
+		if i := m.index(msg.doc.ID); i >= 0 {
+			m.docs[i] = msg.doc
+		}
+		m.status = fmt.Sprintf("%s %q", msg.action, msg.doc.Title)
+		m.clampCursor()
+	case tuiDeleted:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("deleting %q failed: %v", msg.doc.Title, msg.err)
+			break
+		}
+		if i := m.index(msg.doc.ID); i >= 0 {
+			m.docs = slices.Delete(m.docs, i, i+1)
+		}
+		m.status = fmt.Sprintf("deleted %q", msg.doc.Title)
+		m.clampCursor()
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		return m, m.updateKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m *tuiModel) updateKey(msg tea.KeyMsg) tea.Cmd {
+	switch m.mode {
+	case tuiFiltering, tuiTagging:
+		return m.updateInput(msg)
+	case tuiMoving:
+		m.mode = tuiBrowsing
+		if location, ok := tuiMoveKeys[msg.String()]; ok {
+			return m.move(location)
+		}
+		m.status = ""
+		return nil
+	case tuiConfirmDelete:
+		m.mode = tuiBrowsing
+		if msg.String() == "y" {
+			return m.delete()
+		}
+		m.status = ""
+		return nil
+	}
+
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.visible())-1, 0))
+	case "tab":
+		m.location = (m.location + 1) % len(m.locations)
+		m.cursor = 0
+	case "shift+tab":
+		m.location = (m.location + len(m.locations) - 1) % len(m.locations)
+		m.cursor = 0
+	case "c":
+		m.category = (m.category + 1) % len(m.categories)
+		m.cursor = 0
+	case "/":
+		m.mode = tuiFiltering
+	case "esc":
+		m.filter = ""
+		m.clampCursor()
+	case "a":
+		return m.move(readwisereader.LocationArchive)
+	case "m":
+		if m.selected() != nil {
+			m.mode = tuiMoving
+		}
+	case "t":
+		if m.selected() != nil {
+			m.mode, m.input = tuiTagging, ""
+		}
+	case "o":
+		if doc := m.selected(); doc != nil {
+			if err := openBrowser(documentLink(*doc)); err != nil {
+				m.status = fmt.Sprintf("open: %v", err)
+			}
+		}
+	case "d":
+		if m.selected() != nil {
+			m.mode = tuiConfirmDelete
+		}
+	}
+
+	return nil
+}
+
+// updateInput edits the filter or the tags being added.
+func (m *tuiModel) updateInput(msg tea.KeyMsg) tea.Cmd {
+	text := &m.filter
+	if m.mode == tuiTagging {
+		text = &m.input
+	}
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		mode := m.mode
+		m.mode = tuiBrowsing
+		if mode == tuiTagging {
+			return m.tag(m.input)
+		}
+	case tea.KeyEsc:
+		m.mode, *text = tuiBrowsing, ""
+	case tea.KeyBackspace:
+		if *text != "" {
+			r := []rune(*text)
+			*text = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		*text += string(msg.Runes)
+	}
+
+	m.clampCursor()
+	return nil
+}
+
+// move moves the selected document to location.
+func (m *tuiModel) move(location readwisereader.Location) tea.Cmd {
+	doc := m.selected()
+	if doc == nil || doc.Location == location {
+		return nil
+	}
+
+	var params readwisereader.UpdateParams
+	params.SetLocation(location)
+
+	updated := *doc
+	updated.Location = location
+	return m.update(updated, params, "moved to "+string(location))
+}
+
+// tag adds the comma separated tags to the selected document.
+func (m *tuiModel) tag(input string) tea.Cmd {
+	doc := m.selected()
+	if doc == nil {
+		return nil
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(input, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var params readwisereader.UpdateParams
+	params.SetTags(addTags(documentTags(*doc), tags)...)
+
+	updated := *doc
+	updated.Tags = make(map[string]readwisereader.Tag, len(params.Tags))
+	for _, tag := range params.Tags {
+		updated.Tags[strings.ToLower(tag)] = readwisereader.Tag{Name: tag}
+	}
+	return m.update(updated, params, "tagged")
+}
+
+// update sends params to Reader in the background and keeps the cache in
+// step with it.
+func (m *tuiModel) update(doc readwisereader.Document, params readwisereader.UpdateParams, action string) tea.Cmd {
+	m.status = "updating " + doc.Title + "…"
+	return func() tea.Msg {
+		if _, err := m.client.Update(m.ctx, doc.ID, params); err != nil {
+			return tuiUpdated{doc: doc, action: action, err: err}
+		}
+
+		err := m.store.Put(m.ctx, []readwisereader.Document{doc})
+		return tuiUpdated{doc: doc, action: action, err: err}
+	}
+}
+
+func (m *tuiModel) delete() tea.Cmd {
+	doc := m.selected()
+	if doc == nil {
+		return nil
+	}
+
+	d := *doc
+	m.status = "deleting " + d.Title + "…"
+	return func() tea.Msg {
+		if err := m.client.Delete(m.ctx, d.ID); err != nil {
+			return tuiDeleted{doc: d, err: err}
+		}
+
+		err := m.store.Delete(m.ctx, []string{d.ID})
+		return tuiDeleted{doc: d, err: err}
+	}
+}
+
+func (m *tuiModel) index(id string) int {
+	return slices.IndexFunc(m.docs, func(d readwisereader.Document) bool {
+		return d.ID == id
+	})
+}
+
+// visible returns the documents matching the location, category and filter.
+func (m *tuiModel) visible() []readwisereader.Document {
+	location, category := m.locations[m.location], m.categories[m.category]
+	filter := strings.ToLower(m.filter)
+
+	var docs []readwisereader.Document
+	for _, doc := range m.docs {
+		if location != "" && doc.Location != location {
+			continue
+		}
+		if category != "" && doc.Category != category {
+			continue
+		}
+
+		if filter == "" || slices.ContainsFunc([]string{doc.Title, documentAuthor(doc), documentSite(doc)}, func(s string) bool {
+			return strings.Contains(strings.ToLower(s), filter)
+		}) {
+			docs = append(docs, doc)
+		}
+	}
+
+	return docs
+}
+
+func (m *tuiModel) selected() *readwisereader.Document {
+	visible := m.visible()
+	if m.cursor >= len(visible) {
+		return nil
+	}
+
+	return &visible[m.cursor]
+}
+
+func (m *tuiModel) clampCursor() {
+	m.cursor = min(m.cursor, max(len(m.visible())-1, 0))
+}
+
+func (m *tuiModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	// Borders and padding take four columns a pane, borders, header and
+	// footer four rows.
+	listWidth := max(m.width*2/5-4, 10)
+	previewWidth := max(m.width-listWidth-8, 10)
+	rows := max(m.height-5, 1)
+
+	visible := m.visible()
+
+	location, category := "all locations", "all categories"
+	if l := m.locations[m.location]; l != "" {
+		location = string(l)
+	}
+	if c := m.categories[m.category]; c != "" {
+		category = string(c)
+	}
+
+	lines := []string{boardHeaderStyle.Render(truncateRunes(fmt.Sprintf("%s · %s (%d)", location, category, len(visible)), listWidth))}
+
+	// Scroll just enough to keep the cursor in view.
+	offset := max(m.cursor-rows+2, 0)
+	for i := offset; i < len(visible) && i < offset+rows-1; i++ {
+		title := visible[i].Title
+		if title == "" {
+			title = documentLink(visible[i])
+		}
+		line := truncateRunes(title, listWidth)
+		if i == m.cursor {
+			line = boardCursorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	list := tuiFocusedStyle.Width(listWidth + 2).Height(rows).Render(strings.Join(lines, "\n"))
+
+	var preview string
+	if doc := m.selected(); doc != nil {
+		preview = m.preview(*doc, previewWidth)
+	}
+	preview = tuiPaneStyle.Width(previewWidth + 2).Height(rows).MaxHeight(rows + 2).Render(preview)
+
+	footer := boardFaintStyle.Render("j/k document · tab location · c category · / filter · a archive · m move · t tag · o open · d delete · q quit")
+	switch {
+	case m.mode == tuiFiltering:
+		footer = "/" + m.filter + "█"
+	case m.mode == tuiTagging:
+		footer = "tags to add, comma separated: " + m.input + "█"
+	case m.mode == tuiMoving:
+		footer = "move to [n]ew, [l]ater, [s]hortlist, [a]rchive or [f]eed"
+	case m.mode == tuiConfirmDelete:
+		footer = "delete this document? y/n"
+	case m.status != "":
+		footer = m.status
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, preview) + "\n" + footer
+}
+
+// preview renders the metadata and summary of doc.
+func (m *tuiModel) preview(doc readwisereader.Document, width int) string {
+	var b strings.Builder
+
+	b.WriteString(boardHeaderStyle.Render(doc.Title) + "\n")
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s %s\n", boardFaintStyle.Render(name+":"), value)
+		}
+	}
+
+	field("Author", doc.Author)
+	field("Site", documentSite(doc))
+	field("URL", documentLink(doc))
+	field("Location", string(doc.Location))
+	field("Category", string(doc.Category))
+	field("Saved", formatDate(doc.SavedAt))
+	if doc.WordCount > 0 {
+		field("Length", fmt.Sprintf("%d words, %s", doc.WordCount, formatReadingTime(readingTime(doc.WordCount, m.wpm))))
+	}
+	if doc.ReadingProgress > 0 {
+		field("Progress", progressBar(doc.ReadingProgress, 20))
+	}
+	field("Tags", strings.Join(documentTags(doc), ", "))
+
+	if summary := strings.TrimSpace(doc.Summary); summary != "" {
+		b.WriteString("\n" + summary + "\n")
+	}
+	if notes := strings.TrimSpace(doc.Notes); notes != "" {
+		b.WriteString("\n" + boardFaintStyle.Render("Notes:") + "\n" + notes + "\n")
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(b.String())
+}
+
+// openBrowser opens u in the default browser without waiting for it.
+func openBrowser(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	go cmd.Wait()
+	return nil
+}
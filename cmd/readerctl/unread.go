@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"github.com/peterbourgon/ff/v4"
+)
+
+type unreadCmd struct {
+	*rootCmd
+	locations      string
+	cached         bool
+	maxAge         time.Duration
+	promptTemplate string
+	flags          *ff.FlagSet
+	command        *ff.Command
+}
+
+const defaultPromptTemplate = `📚 {{range $i, $c := .Locations}}{{if $i}} · {{end}}{{$c.Count}} {{$c.Location}}{{end}}`
+
+type unreadPrompt struct {
+	Total     int
+	Counts    map[string]int
+	Locations []unreadLocationCount
+}
+
+type unreadLocationCount struct {
+	Location string
+	Count    int
+}
+
+func newUnreadCmd(root *rootCmd) *unreadCmd {
+	cmd := &unreadCmd{rootCmd: root}
+	cmd.flags = ff.NewFlagSet("unread").SetParent(root.flags)
+	cmd.flags.StringVar(&cmd.locations, 0, "location", readwisereader.LocationNew, "comma separated locations to count")
+	cmd.flags.BoolVar(&cmd.cached, 0, "cached", "answer from the local cache instead of the API")
+	cmd.flags.DurationVar(&cmd.maxAge, 0, "max-age", 10*time.Minute, "refresh the cache in the background when older than this, with --cached")
+	cmd.flags.StringVar(&cmd.promptTemplate, 0, "prompt-template", defaultPromptTemplate, "Go template used by --output prompt")
+	cmd.command = &ff.Command{
+		Name:      "unread",
+		Usage:     "readerctl unread [FLAGS]",
+		ShortHelp: "print the number of unread documents",
+		LongHelp: `Meant for shell prompts and status bars; with --cached it never waits for
+the network. --output prompt prints a one line summary, customizable with
+--prompt-template which receives .Total, .Counts (keyed by location) and
+.Locations (a list of .Location and .Count).`,
+		Flags: cmd.flags,
+		Exec:  cmd.exec,
+	}
+
+	root.addCommand(cmd.command)
+	return cmd
+}
+
+func (c *unreadCmd) exec(ctx context.Context, args []string) error {
+	locations, err := parseLocations(c.locations)
+	if err != nil {
+		return err
+	}
+
+	var tmpl *template.Template
+	switch c.output {
+	case outputTable, outputJSON:
+	case outputPrompt:
+		tmpl, err = template.New("prompt").Parse(c.promptTemplate)
+		if err != nil {
+			return fmt.Errorf("--prompt-template: %w", err)
+		}
+	default:
+		return unsupportedOutput(c.output)
+	}
+
+	var counts map[readwisereader.Location]int
+	if c.cached {
+		counts, err = c.cachedCounts(ctx, locations)
+	} else {
+		counts, err = c.remoteCounts(ctx, locations)
+	}
+	if err != nil {
+		return err
+	}
+
+	data := unreadPrompt{
+		Counts: map[string]int{},
+	}
+	for _, location := range locations {
+		n := counts[location]
+		data.Total += n
+		data.Counts[string(location)] = n
+		data.Locations = append(data.Locations, unreadLocationCount{
+			Location: string(location),
+			Count:    n,
+		})
+	}
+
+	switch c.output {
+	case outputJSON:
+		return writeJSON(c.stdout, map[string]any{
+			"total":  data.Total,
+			"counts": data.Counts,
+		})
+	case outputTable:
+		fmt.Fprintln(c.stdout, data.Total)
+		return nil
+	}
+
+	if err := tmpl.Execute(c.stdout, data); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.stdout)
+	return nil
+}
+
+func (c *unreadCmd) remoteCounts(ctx context.Context, locations []readwisereader.Location) (map[readwisereader.Location]int, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[readwisereader.Location]int{}
+	for _, location := range locations {
+		resp, err := client.List(ctx, readwisereader.ListParams{Location: location})
+		if err != nil {
+			return nil, err
+		}
+
+		counts[location] = resp.Count
+	}
+
+	return counts, nil
+}
+
+func (c *unreadCmd) cachedCounts(ctx context.Context, locations []readwisereader.Location) (map[readwisereader.Location]int, error) {
+	store := c.store()
+
+	state, err := store.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Since(state.LastSyncAt) > c.maxAge {
+		if err := c.refreshInBackground(); err != nil {
+			fmt.Fprintf(c.stderr, "background refresh: %v\n", err)
+		}
+	}
+
+	docs, err := store.Documents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[readwisereader.Location]int{}
+	for _, location := range locations {
+		counts[location] = 0
+	}
+
+	for _, doc := range docs {
+		if doc.ParentID != "" {
+			continue
+		}
+
+		if _, ok := counts[doc.Location]; ok {
+			counts[doc.Location]++
+		}
+	}
+
+	return counts, nil
+}
+
+// refreshInBackground starts a detached readerctl sync. A lock file keeps
+// prompts that render many times a second from piling up concurrent syncs.
+func (r *rootCmd) refreshInBackground() error {
+	lock := filepath.Join(r.cacheDir, "refresh.lock")
+	if fi, err := os.Stat(lock); err == nil && time.Since(fi.ModTime()) < time.Minute {
+		return nil
+	}
+
+	if err := os.MkdirAll(r.cacheDir, 0o700); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(lock, nil, 0o600); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	token, _, err := r.resolveToken()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"--config", r.config, "--cache-dir", r.cacheDir}
+	if r.profile != "" {
+		args = append(args, "--profile", r.profile)
+	}
+
+	cmd := exec.Command(exe, append(args, "sync")...)
+	// Pass the token through the environment so it doesn't show up in the
+	// process list.
+	cmd.Env = append(os.Environ(), "READERCTL_TOKEN="+token)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
+
+var knownLocations = []readwisereader.Location{
+	readwisereader.LocationNew,
+	readwisereader.LocationLater,
+	readwisereader.LocationShortList,
+	readwisereader.LocationArchive,
+	readwisereader.LocationFeed,
+}
+
+func parseLocations(s string) ([]readwisereader.Location, error) {
+	var locations []readwisereader.Location
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		location, err := parseLocation(v)
+		if err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, location)
+	}
+
+	return locations, nil
+}
+
+func parseLocation(s string) (readwisereader.Location, error) {
+	for _, location := range knownLocations {
+		if strings.EqualFold(s, string(location)) {
+			return location, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown location: %q", s)
+}
+
+var knownCategories = []readwisereader.Category{
+	readwisereader.CategoryArticle,
+	readwisereader.CategoryEmail,
+	readwisereader.CategoryRSS,
+	readwisereader.CategoryHighlight,
+	readwisereader.CategoryNote,
+	readwisereader.CategoryPDF,
+	readwisereader.CategoryEPUB,
+	readwisereader.CategoryTweet,
+	readwisereader.CategoryVideo,
+}
+
+func parseCategories(s string) ([]readwisereader.Category, error) {
+	var categories []readwisereader.Category
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		category, err := parseCategory(v)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+func parseCategory(s string) (readwisereader.Category, error) {
+	for _, category := range knownCategories {
+		if strings.EqualFold(s, string(category)) {
+			return category, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown category: %q", s)
+}
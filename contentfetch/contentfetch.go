@@ -0,0 +1,288 @@
+// Package contentfetch downloads the content of many documents concurrently,
+// either the HTML Reader parsed or the source pages themselves.
+package contentfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// ErrNoContent is reported for documents there is nothing to download for.
+var ErrNoContent = errors.New("no content")
+
+// maxBodySize caps source page downloads.
+const maxBodySize = 10 << 20
+
+type Mode int
+
+const (
+	// ModeContent uses the HTML content Reader parsed, downloading the source
+	// page only when Reader has none.
+	ModeContent Mode = iota
+	// ModeSource always downloads the source page.
+	ModeSource
+)
+
+type Origin string
+
+const (
+	OriginReader Origin = "reader"
+	OriginSource Origin = "source"
+)
+
+// Result is the content downloaded for a document.
+type Result struct {
+	Document readwisereader.Document
+	// Where Body came from
+	Origin Origin
+	// URL the body was downloaded from, empty for Reader content
+	URL string
+	// Content type of the body
+	ContentType string
+	Body        []byte
+	// Number of attempts made, including the successful one
+	Attempts int
+	// Error encountered after all retries, if any
+	Err error
+}
+
+// Fetcher downloads document content with a pool of workers. The zero value is
+// usable.
+type Fetcher struct {
+	// API used to fetch the HTML content of documents listed without it, if
+	// nil documents without content fall back to their source page
+	API readwisereader.API
+	// Client used for source pages, defaults to one with a 30 second timeout
+	Client *http.Client
+	// Number of concurrent downloads, defaults to 4
+	Workers int
+	// Number of retries for rate limited, failed or 5xx requests, defaults to
+	// 2, negative disables retries
+	Retries int
+	Mode    Mode
+}
+
+// Fetch downloads the content of every document in docs, skipping highlights
+// and notes, and calls fn with each result as it completes, not in the order
+// of docs. fn is never called concurrently. Failed downloads are reported
+// through Result.Err; Fetch returns early with the error of docs or fn.
+func (f *Fetcher) Fetch(ctx context.Context, docs iter.Seq2[readwisereader.Document, error], fn func(Result) error) error {
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	workers := f.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan readwisereader.Document)
+	results := make(chan Result)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(jobs)
+		for doc, err := range docs {
+			if err != nil {
+				errc <- err
+				return
+			}
+
+			if doc.ParentID != "" {
+				continue
+			}
+
+			select {
+			case jobs <- doc:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for doc := range jobs {
+				select {
+				case results <- f.fetch(ctx, client, doc):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	defer func() {
+		cancel()
+		for range results {
+		}
+	}()
+
+	for r := range results {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case err := <-errc:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
+func (f *Fetcher) retries() int {
+	switch {
+	case f.Retries < 0:
+		return 0
+	case f.Retries == 0:
+		return 2
+	default:
+		return f.Retries
+	}
+}
+
+func (f *Fetcher) fetch(ctx context.Context, client *http.Client, doc readwisereader.Document) Result {
+	r := Result{Document: doc}
+
+	for attempt := 0; ; attempt++ {
+		r.Attempts = attempt + 1
+		err := f.fetchOnce(ctx, client, &r)
+		if err == nil {
+			return r
+		}
+
+		wait, retry := retryAfter(err, attempt)
+		if !retry || attempt >= f.retries() {
+			r.Err = err
+			return r
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			r.Err = ctx.Err()
+			return r
+		}
+	}
+}
+
+func (f *Fetcher) fetchOnce(ctx context.Context, client *http.Client, r *Result) error {
+	if f.Mode == ModeContent {
+		html := r.Document.HTMLContent
+		if html == "" && f.API != nil {
+			// The client waits out rate limits itself.
+			resp, err := f.API.List(readwisereader.WaitOutRateLimits(ctx), readwisereader.ListParams{ID: r.Document.ID, WithHTMLContent: true})
+			if err != nil {
+				return err
+			}
+			if len(resp.Results) > 0 {
+				html = resp.Results[0].HTMLContent
+			}
+		}
+
+		if html != "" {
+			r.Origin = OriginReader
+			r.ContentType = "text/html; charset=utf-8"
+			r.Body = []byte(html)
+			return nil
+		}
+	}
+
+	u := r.Document.SourceURL
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return ErrNoContent
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{url: u, code: resp.StatusCode, retryAfter: resp.Header.Get("Retry-After")}
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	r.Origin = OriginSource
+	r.URL = u
+	r.ContentType = resp.Header.Get("Content-Type")
+	r.Body = b
+	return nil
+}
+
+type statusError struct {
+	url        string
+	code       int
+	retryAfter string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("fetch %s: unexpected status code: %d", e.url, e.code)
+}
+
+// retryAfter reports whether err is worth retrying and how long to wait before
+// doing so.
+func retryAfter(err error, attempt int) (time.Duration, bool) {
+	backoff := 500 * time.Millisecond << attempt
+
+	var se *statusError
+	if errors.As(err, &se) {
+		switch {
+		case se.code == http.StatusTooManyRequests:
+			if seconds, err := strconv.Atoi(se.retryAfter); err == nil {
+				return time.Duration(seconds) * time.Second, true
+			}
+			return backoff, true
+		case se.code >= 500:
+			return backoff, true
+		default:
+			return 0, false
+		}
+	}
+
+	var ae *readwisereader.APIError
+	if errors.As(err, &ae) {
+		return backoff, ae.StatusCode >= 500
+	}
+
+	if errors.Is(err, ErrNoContent) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	// Anything else is a transport error, connection resets and the like.
+	return backoff, true
+}
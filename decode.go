@@ -0,0 +1,13 @@
+//go:build !(goexperiment.jsonv2 && go1.27)
+
+package readwisereader
+
+import "encoding/json"
+
+// decodeJSON decodes API responses. Go 1.27 or later with the jsonv2
+// experiment enabled, as it is by default, swaps in encoding/json/v2 with
+// encoding/json's options, so both decode alike. GOEXPERIMENT=nojsonv2 keeps
+// encoding/json.
+func decodeJSON(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,13 @@
+//go:build goexperiment.jsonv2 && go1.27
+
+package readwisereader
+
+import (
+	jsonv1 "encoding/json"
+	"encoding/json/v2"
+)
+
+func decodeJSON(data []byte, v any) error {
+	// Decode exactly like encoding/json does, only through the v2 API.
+	return json.Unmarshal(data, v, jsonv1.DefaultOptionsV1())
+}
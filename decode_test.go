@@ -0,0 +1,38 @@
+package readwisereader
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// BenchmarkDecodeJSON compares the decoding backend the build picked with
+// encoding/json. Run it with GOEXPERIMENT=jsonv2 and GOEXPERIMENT=nojsonv2 to
+// compare both backends.
+func BenchmarkDecodeJSON(b *testing.B) {
+	data, err := os.ReadFile("readwisereadertest/fixtures/list.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	decoders := []struct {
+		name   string
+		decode func(data []byte, v any) error
+	}{
+		{"encoding/json", json.Unmarshal},
+		{"backend", decodeJSON},
+	}
+
+	for _, d := range decoders {
+		b.Run(d.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for range b.N {
+				var lr listResponse
+				if err := d.decode(data, &lr); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package readwisereader
+
+import (
+	"iter"
+	"slices"
+)
+
+// DocumentSet is a collection of documents keyed by ID. Iteration follows
+// insertion order; adding a document that is already present replaces it in
+// place. The zero value is an empty set ready to use.
+type DocumentSet struct {
+	ids  []string
+	docs map[string]Document
+}
+
+func NewDocumentSet(docs ...Document) *DocumentSet {
+	s := &DocumentSet{}
+	s.Add(docs...)
+	return s
+}
+
+func (s *DocumentSet) Add(docs ...Document) {
+	if s.docs == nil {
+		s.docs = map[string]Document{}
+	}
+
+	for _, doc := range docs {
+		if _, ok := s.docs[doc.ID]; !ok {
+			s.ids = append(s.ids, doc.ID)
+		}
+		s.docs[doc.ID] = doc
+	}
+}
+
+func (s *DocumentSet) Remove(ids ...string) {
+	for _, id := range ids {
+		delete(s.docs, id)
+	}
+
+	s.ids = slices.DeleteFunc(s.ids, func(id string) bool {
+		_, ok := s.docs[id]
+		return !ok
+	})
+}
+
+func (s *DocumentSet) Get(id string) (Document, bool) {
+	doc, ok := s.docs[id]
+	return doc, ok
+}
+
+func (s *DocumentSet) Has(id string) bool {
+	_, ok := s.docs[id]
+	return ok
+}
+
+func (s *DocumentSet) Len() int {
+	return len(s.ids)
+}
+
+func (s *DocumentSet) All() iter.Seq[Document] {
+	return func(yield func(Document) bool) {
+		for _, id := range s.ids {
+			if !yield(s.docs[id]) {
+				return
+			}
+		}
+	}
+}
+
+func (s *DocumentSet) IDs() []string {
+	return slices.Clone(s.ids)
+}
+
+func (s *DocumentSet) Documents() []Document {
+	return slices.Collect(s.All())
+}
+
+// Filter returns the documents for which fn returns true.
+func (s *DocumentSet) Filter(fn func(Document) bool) *DocumentSet {
+	out := &DocumentSet{}
+	for doc := range s.All() {
+		if fn(doc) {
+			out.Add(doc)
+		}
+	}
+
+	return out
+}
+
+// Union returns the documents in either set, preferring other's copy of
+// documents present in both.
+func (s *DocumentSet) Union(other *DocumentSet) *DocumentSet {
+	out := &DocumentSet{}
+	out.Add(s.Documents()...)
+	out.Add(other.Documents()...)
+	return out
+}
+
+// Diff returns the documents in s that aren't in other.
+func (s *DocumentSet) Diff(other *DocumentSet) *DocumentSet {
+	return s.Filter(func(doc Document) bool { return !other.Has(doc.ID) })
+}
+
+// Intersect returns the documents in s that are also in other.
+func (s *DocumentSet) Intersect(other *DocumentSet) *DocumentSet {
+	return s.Filter(func(doc Document) bool { return other.Has(doc.ID) })
+}
+
+// ByTag indexes the documents by tag name. A document with several tags
+// appears under each of them.
+func (s *DocumentSet) ByTag() map[string][]Document {
+	index := map[string][]Document{}
+	for doc := range s.All() {
+		for tag := range doc.Tags {
+			index[tag] = append(index[tag], doc)
+		}
+	}
+
+	return index
+}
+
+func (s *DocumentSet) ByLocation() map[Location][]Document {
+	index := map[Location][]Document{}
+	for doc := range s.All() {
+		index[doc.Location] = append(index[doc.Location], doc)
+	}
+
+	return index
+}
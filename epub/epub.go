@@ -0,0 +1,281 @@
+// Package epub writes minimal EPUB 3 books from HTML documents.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Chapter is a single document of the book.
+type Chapter struct {
+	Title  string
+	Author string
+	// HTML of the chapter, a fragment or a whole page
+	HTML string
+}
+
+// Book is an EPUB to be written.
+type Book struct {
+	Title    string
+	Author   string
+	Language string
+	Chapters []Chapter
+}
+
+// Write writes b as an EPUB to w.
+func (b *Book) Write(w io.Writer) error {
+	if len(b.Chapters) == 0 {
+		return fmt.Errorf("epub: no chapters")
+	}
+
+	id, err := uuid()
+	if err != nil {
+		return err
+	}
+
+	lang := b.Language
+	if lang == "" {
+		lang = "en"
+	}
+
+	data := bookData{
+		ID:       id,
+		Title:    b.Title,
+		Author:   b.Author,
+		Language: lang,
+		Modified: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	for i, ch := range b.Chapters {
+		data.Chapters = append(data.Chapters, chapterData{
+			ID:    fmt.Sprintf("chapter%d", i+1),
+			File:  fmt.Sprintf("chapter%d.xhtml", i+1),
+			Title: ch.Title,
+		})
+	}
+
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry goes first and uncompressed so that readers can
+	// sniff the format.
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		tmpl *template.Template
+		data any
+	}{
+		{"META-INF/container.xml", containerTmpl, nil},
+		{"OEBPS/content.opf", opfTmpl, data},
+		{"OEBPS/nav.xhtml", navTmpl, data},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if err := f.tmpl.Execute(fw, f.data); err != nil {
+			return fmt.Errorf("epub: %s: %w", f.name, err)
+		}
+	}
+
+	for i, ch := range b.Chapters {
+		body, err := xhtmlBody(ch.HTML)
+		if err != nil {
+			return fmt.Errorf("epub: chapter %d: %w", i+1, err)
+		}
+
+		fw, err := zw.Create("OEBPS/" + data.Chapters[i].File)
+		if err != nil {
+			return err
+		}
+
+		err = chapterTmpl.Execute(fw, map[string]any{
+			"Title":    ch.Title,
+			"Author":   ch.Author,
+			"Language": lang,
+			"Body":     body,
+		})
+		if err != nil {
+			return fmt.Errorf("epub: chapter %d: %w", i+1, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+type bookData struct {
+	ID       string
+	Title    string
+	Author   string
+	Language string
+	Modified string
+	Chapters []chapterData
+}
+
+type chapterData struct {
+	ID    string
+	File  string
+	Title string
+}
+
+// xhtmlBody parses an HTML document or fragment and renders the contents of
+// its body as well-formed XHTML, without scripts, styles or forms.
+func xhtmlBody(s string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return "", err
+	}
+
+	var body *html.Node
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Body {
+			body = n
+			break
+		}
+	}
+	if body == nil {
+		return "", nil
+	}
+
+	var remove []*html.Node
+	for n := range body.Descendants() {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript, atom.Iframe, atom.Form, atom.Object, atom.Embed:
+				remove = append(remove, n)
+			}
+			if strings.Contains(n.Data, ":") {
+				remove = append(remove, n)
+			}
+			// Attributes like onclick or unknown namespaces trip up strict
+			// readers.
+			attrs := n.Attr[:0]
+			for _, a := range n.Attr {
+				if a.Namespace == "" && !strings.HasPrefix(a.Key, "on") && !strings.Contains(a.Key, ":") {
+					attrs = append(attrs, a)
+				}
+			}
+			n.Attr = attrs
+
+			// Foreign content needs its namespace spelled out in XHTML.
+			if n.Namespace == "svg" && n.Data == "svg" {
+				n.Attr = append(n.Attr, html.Attribute{Key: "xmlns", Val: "http://www.w3.org/2000/svg"})
+			}
+			if n.Namespace == "math" && n.Data == "math" {
+				n.Attr = append(n.Attr, html.Attribute{Key: "xmlns", Val: "http://www.w3.org/1998/Math/MathML"})
+			}
+		} else if n.Type == html.CommentNode {
+			remove = append(remove, n)
+		}
+	}
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+
+	var buf bytes.Buffer
+	for c := body.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return "", err
+		}
+	}
+
+	return buf.String(), nil
+}
+
+func uuid() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func escape(s string) (string, error) {
+	var b strings.Builder
+	err := xml.EscapeText(&b, []byte(s))
+	return b.String(), err
+}
+
+var funcs = template.FuncMap{"x": escape}
+
+var containerTmpl = template.Must(template.New("container").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`))
+
+var opfTmpl = template.Must(template.New("opf").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="bookid">urn:uuid:{{ .ID }}</dc:identifier>
+    <dc:title>{{ x .Title }}</dc:title>
+    {{- if .Author }}
+    <dc:creator>{{ x .Author }}</dc:creator>
+    {{- end }}
+    <dc:language>{{ x .Language }}</dc:language>
+    <meta property="dcterms:modified">{{ .Modified }}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    {{- range .Chapters }}
+    <item id="{{ .ID }}" href="{{ .File }}" media-type="application/xhtml+xml"/>
+    {{- end }}
+  </manifest>
+  <spine>
+    {{- range .Chapters }}
+    <itemref idref="{{ .ID }}"/>
+    {{- end }}
+  </spine>
+</package>
+`))
+
+var navTmpl = template.Must(template.New("nav").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{ x .Language }}">
+<head><title>{{ x .Title }}</title></head>
+<body>
+  <nav epub:type="toc">
+    <h1>{{ x .Title }}</h1>
+    <ol>
+      {{- range .Chapters }}
+      <li><a href="{{ .File }}">{{ x .Title }}</a></li>
+      {{- end }}
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+var chapterTmpl = template.Must(template.New("chapter").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{ x .Language }}">
+<head><title>{{ x .Title }}</title></head>
+<body>
+<h1>{{ x .Title }}</h1>
+{{- if .Author }}
+<p><em>{{ x .Author }}</em></p>
+{{- end }}
+{{ .Body }}
+</body>
+</html>
+`))
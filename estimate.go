@@ -0,0 +1,84 @@
+package readwisereader
+
+import (
+	"sync"
+	"time"
+)
+
+// listRequestsPerMinute is the rate limit of the list endpoint, which bounds
+// how fast pagination can go regardless of observed throughput.
+const listRequestsPerMinute = 20
+
+// Estimate is a snapshot of pagination progress.
+type Estimate struct {
+	// Documents yielded so far
+	Done int
+	// Total number of documents, as reported by the API
+	Total          int
+	Remaining      int
+	Pages          int
+	PagesRemaining int
+	// Documents per second since the estimator started, rate limit waits
+	// included
+	Throughput float64
+	// Estimated time until the last page, zero once done or before the
+	// first page
+	ETA time.Duration
+}
+
+// Estimator estimates how long a ListPaginate run has left from the pages
+// observed so far. It is safe for concurrent use.
+type Estimator struct {
+	mu      sync.Mutex
+	started time.Time
+	total   int
+	done    int
+	pages   int
+}
+
+// NewEstimator returns an estimator for a run starting now.
+func NewEstimator() *Estimator {
+	return &Estimator{started: time.Now()}
+}
+
+// Observe records a page yielded by ListPaginate.
+func (e *Estimator) Observe(page Page) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.total = page.Count
+	e.done += len(page.Results)
+	e.pages++
+}
+
+func (e *Estimator) Estimate() Estimate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	est := Estimate{
+		Done:      e.done,
+		Total:     max(e.total, e.done),
+		Remaining: max(e.total-e.done, 0),
+		Pages:     e.pages,
+	}
+
+	if e.pages == 0 || e.done == 0 {
+		return est
+	}
+
+	perPage := float64(e.done) / float64(e.pages)
+	est.PagesRemaining = int((float64(est.Remaining) + perPage - 1) / perPage)
+
+	elapsed := time.Since(e.started)
+	if elapsed > 0 {
+		est.Throughput = float64(e.done) / elapsed.Seconds()
+	}
+
+	if est.Remaining > 0 && est.Throughput > 0 {
+		eta := time.Duration(float64(est.Remaining) / est.Throughput * float64(time.Second))
+		limited := time.Duration(est.PagesRemaining) * time.Minute / listRequestsPerMinute
+		est.ETA = max(eta, limited).Round(time.Second)
+	}
+
+	return est
+}
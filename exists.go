@@ -0,0 +1,51 @@
+package readwisereader
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// ExistsByURL looks for a saved document whose source URL matches u. It
+// returns a nil document when there is none. The API has no URL filter, so
+// this walks the whole library; keep a local mirror when calling it often.
+func (c *Client) ExistsByURL(ctx context.Context, u string) (*Document, error) {
+	for doc, err := range c.Documents(ctx, ListParams{}) {
+		if err != nil {
+			return nil, err
+		}
+
+		if doc.ParentID == "" && doc.HasURL(u) {
+			return &doc, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// HasURL reports whether the document's source or Reader URL points to u,
+// ignoring differences such as the scheme or a trailing slash.
+func (d Document) HasURL(u string) bool {
+	want := NormalizeURL(u)
+	return NormalizeURL(d.SourceURL) == want || NormalizeURL(d.URL) == want
+}
+
+// NormalizeURL drops the parts of a URL that don't change what it points to
+// in practice: the scheme, a leading www., the fragment and a trailing slash.
+// HasURL matches URLs that normalize the same.
+func NormalizeURL(u string) string {
+	parsed, err := url.Parse(strings.TrimSpace(u))
+	if err != nil || parsed.Host == "" {
+		return strings.TrimSpace(u)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	path := strings.TrimSuffix(parsed.EscapedPath(), "/")
+
+	normalized := host + path
+	if parsed.RawQuery != "" {
+		normalized += "?" + parsed.RawQuery
+	}
+
+	return normalized
+}
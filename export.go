@@ -0,0 +1,266 @@
+package readwisereader
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// ExportParams filter the highlights export of the classic Readwise API.
+type ExportParams struct {
+	// UpdatedAfter limits the export to books with highlights updated since.
+	UpdatedAfter time.Time `url:"updatedAfter,omitempty"`
+	// IDs limits the export to these books.
+	IDs            []int  `url:"ids,comma,omitempty"`
+	IncludeDeleted bool   `url:"includeDeleted,omitempty"`
+	PageCursor     string `url:"pageCursor,omitempty"`
+}
+
+type ExportResponse struct {
+	Count          int
+	NextPageCursor string
+	Books          []Book
+}
+
+// Book is a source of highlights in Readwise, a book, an article, a tweet or
+// a podcast; Reader documents show up here once they are highlighted.
+type Book struct {
+	ID            int
+	Title         string
+	ReadableTitle string
+	Author        string
+	Source        string
+	Category      string
+	CoverImageURL string
+	UniqueURL     string
+	SourceURL     string
+	ReadwiseURL   string
+	Summary       string
+	DocumentNote  string
+	ExternalID    string
+	ASIN          string
+	Tags          []HighlightTag
+	Deleted       bool
+	Highlights    []Highlight
+}
+
+type Highlight struct {
+	ID            int
+	BookID        int
+	Text          string
+	Note          string
+	Color         string
+	Location      int
+	EndLocation   int
+	LocationType  string
+	URL           string
+	ExternalID    string
+	ReadwiseURL   string
+	Tags          []HighlightTag
+	Favorite      bool
+	Discarded     bool
+	Deleted       bool
+	HighlightedAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type exportResponse struct {
+	Count          int          `json:"count"`
+	NextPageCursor *string      `json:"nextPageCursor"`
+	Results        []exportBook `json:"results"`
+}
+
+type exportBook struct {
+	UserBookID    int               `json:"user_book_id"`
+	Title         string            `json:"title"`
+	ReadableTitle string            `json:"readable_title"`
+	Author        string            `json:"author"`
+	Source        string            `json:"source"`
+	Category      string            `json:"category"`
+	CoverImageURL string            `json:"cover_image_url"`
+	UniqueURL     *string           `json:"unique_url"`
+	SourceURL     *string           `json:"source_url"`
+	ReadwiseURL   string            `json:"readwise_url"`
+	Summary       *string           `json:"summary"`
+	DocumentNote  *string           `json:"document_note"`
+	ExternalID    *string           `json:"external_id"`
+	ASIN          *string           `json:"asin"`
+	BookTags      []highlightTag    `json:"book_tags"`
+	IsDeleted     bool              `json:"is_deleted"`
+	Highlights    []exportHighlight `json:"highlights"`
+}
+
+type exportHighlight struct {
+	ID            int            `json:"id"`
+	BookID        int            `json:"book_id"`
+	Text          string         `json:"text"`
+	Note          string         `json:"note"`
+	Color         string         `json:"color"`
+	Location      *int           `json:"location"`
+	EndLocation   *int           `json:"end_location"`
+	LocationType  string         `json:"location_type"`
+	URL           *string        `json:"url"`
+	ExternalID    *string        `json:"external_id"`
+	ReadwiseURL   string         `json:"readwise_url"`
+	Tags          []highlightTag `json:"tags"`
+	IsFavorite    bool           `json:"is_favorite"`
+	IsDiscard     bool           `json:"is_discard"`
+	IsDeleted     bool           `json:"is_deleted"`
+	HighlightedAt *time.Time     `json:"highlighted_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+func (er *exportResponse) toExportResponse() ExportResponse {
+	books := make([]Book, 0, len(er.Results))
+	for _, b := range er.Results {
+		books = append(books, b.toBook())
+	}
+
+	return ExportResponse{
+		Count:          er.Count,
+		NextPageCursor: deref(er.NextPageCursor),
+		Books:          books,
+	}
+}
+
+func (b *exportBook) toBook() Book {
+	highlights := make([]Highlight, 0, len(b.Highlights))
+	for _, h := range b.Highlights {
+		highlights = append(highlights, h.toHighlight())
+	}
+
+	return Book{
+		ID:            b.UserBookID,
+		Title:         b.Title,
+		ReadableTitle: b.ReadableTitle,
+		Author:        b.Author,
+		Source:        b.Source,
+		Category:      b.Category,
+		CoverImageURL: b.CoverImageURL,
+		UniqueURL:     deref(b.UniqueURL),
+		SourceURL:     deref(b.SourceURL),
+		ReadwiseURL:   b.ReadwiseURL,
+		Summary:       deref(b.Summary),
+		DocumentNote:  deref(b.DocumentNote),
+		ExternalID:    deref(b.ExternalID),
+		ASIN:          deref(b.ASIN),
+		Tags:          toHighlightTags(b.BookTags),
+		Deleted:       b.IsDeleted,
+		Highlights:    highlights,
+	}
+}
+
+func (h *exportHighlight) toHighlight() Highlight {
+	return Highlight{
+		ID:            h.ID,
+		BookID:        h.BookID,
+		Text:          h.Text,
+		Note:          h.Note,
+		Color:         h.Color,
+		Location:      deref(h.Location),
+		EndLocation:   deref(h.EndLocation),
+		LocationType:  h.LocationType,
+		URL:           deref(h.URL),
+		ExternalID:    deref(h.ExternalID),
+		ReadwiseURL:   h.ReadwiseURL,
+		Tags:          toHighlightTags(h.Tags),
+		Favorite:      h.IsFavorite,
+		Discarded:     h.IsDiscard,
+		Deleted:       h.IsDeleted,
+		HighlightedAt: deref(h.HighlightedAt),
+		CreatedAt:     h.CreatedAt,
+		UpdatedAt:     h.UpdatedAt,
+	}
+}
+
+func toHighlightTags(ts []highlightTag) []HighlightTag {
+	tags := make([]HighlightTag, 0, len(ts))
+	for _, t := range ts {
+		tags = append(tags, t.toHighlightTag())
+	}
+
+	return tags
+}
+
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+
+	return *p
+}
+
+// Export returns a page of books and their highlights from the classic
+// Readwise API.
+func (c *Client) Export(ctx context.Context, params ExportParams) (*ExportResponse, error) {
+	er, err := c.export(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	r := er.toExportResponse()
+	return &r, nil
+}
+
+// ExportPaginate yields every book matching params, following page cursors
+// and waiting out rate limits.
+func (c *Client) ExportPaginate(ctx context.Context, params ExportParams) iter.Seq2[Book, error] {
+	return func(yield func(Book, error) bool) {
+		ctx := WaitOutRateLimits(ctx)
+		for {
+			resp, err := c.Export(ctx, params)
+			if err != nil {
+				yield(Book{}, err)
+				return
+			}
+
+			for _, book := range resp.Books {
+				if !yield(book, nil) {
+					return
+				}
+			}
+
+			if resp.NextPageCursor == "" {
+				return
+			}
+
+			params.PageCursor = resp.NextPageCursor
+		}
+	}
+}
+
+func (c *Client) export(ctx context.Context, params ExportParams) (*exportResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.addrV2+"/export/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := query.Values(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var er exportResponse
+	if err := decodeBody(resp, &er); err != nil {
+		return nil, err
+	}
+
+	return &er, nil
+}
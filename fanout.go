@@ -0,0 +1,157 @@
+package readwisereader
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// fanOutConcurrency bounds the number of listings ListFanOut runs at once so
+// a wide fan out doesn't burn through the rate limit in one go.
+const fanOutConcurrency = 4
+
+// ListFanOut lists documents matching any of params.Locations and
+// params.Categories, combined with the rest of params. The listings run
+// concurrently and their results are merged, yielding each document once.
+// Documents are yielded in no particular order.
+func (c *Client) ListFanOut(ctx context.Context, params ListParams) iter.Seq2[Document, error] {
+	var listings []listing
+	for _, p := range fanOutParams(params) {
+		listings = append(listings, listing{params: p})
+	}
+
+	return c.mergeListings(ctx, listings)
+}
+
+// listing is one of the listings merged by mergeListings.
+type listing struct {
+	params ListParams
+	// When set, documents updated at or after before are skipped. The
+	// listing still pages to its end, the API doesn't bound update times
+	// from above nor promise an order.
+	before time.Time
+	// first is the first page of the listing when it was fetched already,
+	// the listing carries on from its cursor.
+	first *ListResponse
+}
+
+// mergeListings runs listings concurrently, yielding each document once.
+func (c *Client) mergeListings(ctx context.Context, listings []listing) iter.Seq2[Document, error] {
+	return func(yield func(Document, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		results := make(chan Result[Document])
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, fanOutConcurrency)
+
+		for _, l := range listings {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					return
+				}
+
+				// send reports whether the listing goes on after docs.
+				send := func(docs []Document) bool {
+					for _, doc := range docs {
+						if !l.before.IsZero() && !doc.UpdatedAt.Before(l.before) {
+							continue
+						}
+
+						select {
+						case results <- Result[Document]{Value: doc}:
+						case <-ctx.Done():
+							return false
+						}
+					}
+					return true
+				}
+
+				params := l.params
+				if l.first != nil {
+					if !send(l.first.Results) || l.first.NextPageCursor == "" {
+						return
+					}
+					params.PageCursor = l.first.NextPageCursor
+				}
+
+				for page, err := range c.ListPaginate(ctx, params) {
+					if err != nil {
+						select {
+						case results <- Result[Document]{Err: err}:
+						case <-ctx.Done():
+						}
+						return
+					}
+
+					if !send(page.Results) {
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		// Cancel and drain before returning so no goroutine outlives the
+		// iteration.
+		defer func() {
+			cancel()
+			for range results {
+			}
+		}()
+
+		seen := map[string]bool{}
+		for r := range results {
+			if r.Err != nil {
+				yield(Document{}, r.Err)
+				return
+			}
+
+			if seen[r.Value.ID] {
+				continue
+			}
+			seen[r.Value.ID] = true
+
+			if !yield(r.Value, nil) {
+				return
+			}
+		}
+	}
+}
+
+func fanOutParams(params ListParams) []ListParams {
+	locations := params.Locations
+	if len(locations) == 0 {
+		locations = []Location{params.Location}
+	}
+
+	categories := params.Categories
+	if len(categories) == 0 {
+		categories = []Category{params.Category}
+	}
+
+	var all []ListParams
+	for _, location := range locations {
+		for _, category := range categories {
+			p := params
+			p.Location = location
+			p.Category = category
+			p.Locations = nil
+			p.Categories = nil
+			all = append(all, p)
+		}
+	}
+
+	return all
+}
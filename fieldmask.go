@@ -0,0 +1,144 @@
+package readwisereader
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// UpdateField names a field of UpdateParams for its field mask.
+type UpdateField string
+
+const (
+	UpdateTitle           UpdateField = "title"
+	UpdateAuthor          UpdateField = "author"
+	UpdateSummary         UpdateField = "summary"
+	UpdatePublishedDate   UpdateField = "published_date"
+	UpdateImageURL        UpdateField = "image_url"
+	UpdateLocation        UpdateField = "location"
+	UpdateCategory        UpdateField = "category"
+	UpdateTags            UpdateField = "tags"
+	UpdateNotes           UpdateField = "notes"
+	UpdateReadingProgress UpdateField = "reading_progress"
+)
+
+// MarshalJSON sends every non-zero field when there is no field mask, and
+// exactly the masked fields otherwise, zero values included.
+func (p UpdateParams) MarshalJSON() ([]byte, error) {
+	type plain UpdateParams
+	if len(p.Fields) == 0 {
+		return json.Marshal(plain(p))
+	}
+
+	var publishedDate any
+	if p.PublishedDate != nil {
+		publishedDate = p.PublishedDate
+	}
+
+	tags := p.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	values := map[UpdateField]any{
+		UpdateTitle:           valueOf(p.Title),
+		UpdateAuthor:          valueOf(p.Author),
+		UpdateSummary:         valueOf(p.Summary),
+		UpdatePublishedDate:   publishedDate,
+		UpdateImageURL:        valueOf(p.ImageURL),
+		UpdateLocation:        p.Location,
+		UpdateCategory:        p.Category,
+		UpdateTags:            tags,
+		UpdateNotes:           valueOf(p.Notes),
+		UpdateReadingProgress: valueOf(p.ReadingProgress),
+	}
+
+	m := make(map[UpdateField]any, len(p.Fields))
+	for _, f := range p.Fields {
+		v, ok := values[f]
+		if !ok {
+			return nil, fmt.Errorf("unknown update field: %q", f)
+		}
+		m[f] = v
+	}
+
+	return json.Marshal(m)
+}
+
+func valueOf[T any](p *T) T {
+	var v T
+	if p != nil {
+		v = *p
+	}
+	return v
+}
+
+func (p *UpdateParams) mask(f UpdateField) *UpdateParams {
+	for _, existing := range p.Fields {
+		if existing == f {
+			return p
+		}
+	}
+
+	p.Fields = append(p.Fields, f)
+	return p
+}
+
+// The Set methods assign a field and add it to the field mask, so that it is
+// sent even when set to its zero value. Once one is used, fields not set
+// through them are left untouched.
+
+func (p *UpdateParams) SetTitle(title string) *UpdateParams {
+	p.Title = &title
+	return p.mask(UpdateTitle)
+}
+
+func (p *UpdateParams) SetAuthor(author string) *UpdateParams {
+	p.Author = &author
+	return p.mask(UpdateAuthor)
+}
+
+func (p *UpdateParams) SetSummary(summary string) *UpdateParams {
+	p.Summary = &summary
+	return p.mask(UpdateSummary)
+}
+
+// SetPublishedDate sets the published date, the zero time clears it.
+func (p *UpdateParams) SetPublishedDate(date time.Time) *UpdateParams {
+	p.PublishedDate = nil
+	if !date.IsZero() {
+		p.PublishedDate = &date
+	}
+	return p.mask(UpdatePublishedDate)
+}
+
+func (p *UpdateParams) SetImageURL(u string) *UpdateParams {
+	p.ImageURL = &u
+	return p.mask(UpdateImageURL)
+}
+
+func (p *UpdateParams) SetLocation(location Location) *UpdateParams {
+	p.Location = location
+	return p.mask(UpdateLocation)
+}
+
+func (p *UpdateParams) SetCategory(category Category) *UpdateParams {
+	p.Category = category
+	return p.mask(UpdateCategory)
+}
+
+// SetTags replaces the tags, no tags clears them.
+func (p *UpdateParams) SetTags(tags ...string) *UpdateParams {
+	p.Tags = tags
+	return p.mask(UpdateTags)
+}
+
+func (p *UpdateParams) SetNotes(notes string) *UpdateParams {
+	p.Notes = &notes
+	return p.mask(UpdateNotes)
+}
+
+func (p *UpdateParams) SetReadingProgress(progress float64) *UpdateParams {
+	p.ReadingProgress = &progress
+	return p.mask(UpdateReadingProgress)
+}
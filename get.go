@@ -0,0 +1,49 @@
+package readwisereader
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetOption configures Get.
+type GetOption func(*getOptions)
+
+type getOptions struct {
+	params ListParams
+	cached bool
+}
+
+// IncludeHTMLContent makes Get fetch the html content of the document too.
+func IncludeHTMLContent() GetOption {
+	return func(o *getOptions) {
+		o.params.WithHTMLContent = true
+	}
+}
+
+// BypassCache makes Get ask the API even when the client has a cached copy.
+func BypassCache() GetOption {
+	return func(o *getOptions) {
+		o.cached = false
+	}
+}
+
+// Get returns the document with the given ID, or ErrNotFound if there is
+// none.
+func (c *Client) Get(ctx context.Context, ID string, opts ...GetOption) (*Document, error) {
+	o := getOptions{params: ListParams{ID: ID}, cached: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	lr, err := c.list(ctx, o.params, o.cached)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lr.Results) == 0 {
+		return nil, fmt.Errorf("document %s: %w", ID, ErrNotFound)
+	}
+
+	doc := lr.Results[0].toDocument()
+	return &doc, nil
+}
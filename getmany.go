@@ -0,0 +1,73 @@
+package readwisereader
+
+import (
+	"context"
+	"sync"
+)
+
+// GetResult is the outcome of looking up one ID with GetMany.
+type GetResult struct {
+	Document Document
+	// ErrNotFound if there is no document with the ID, or whatever else went
+	// wrong looking it up
+	Err error
+}
+
+// getManyConcurrency bounds the lookups GetMany runs at once, the scheduler
+// bounds requests further.
+const getManyConcurrency = maxInFlight
+
+// GetMany looks up documents by ID, one list call per ID as the API takes a
+// single ID at a time, running several concurrently and waiting out rate
+// limits. The result has an entry for every distinct ID. The error is only
+// non-nil if ctx ends before every ID was looked up, the IDs left out then
+// carry ctx's error.
+func (c *Client) GetMany(ctx context.Context, ids []string) (map[string]GetResult, error) {
+	results := make(map[string]GetResult, len(ids))
+	var mu sync.Mutex
+
+	sem := make(chan struct{}, getManyConcurrency)
+	var wg sync.WaitGroup
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			for _, id := range ids {
+				if _, ok := results[id]; !ok {
+					results[id] = GetResult{Err: ctx.Err()}
+				}
+			}
+			return results, ctx.Err()
+		}
+		seen[id] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			r := c.lookup(ctx, id)
+			mu.Lock()
+			results[id] = r
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+func (c *Client) lookup(ctx context.Context, id string) GetResult {
+	doc, err := c.Get(WaitOutRateLimits(ctx), id)
+	if err != nil {
+		return GetResult{Err: err}
+	}
+
+	return GetResult{Document: *doc}
+}
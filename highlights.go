@@ -0,0 +1,92 @@
+package readwisereader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// HighlightParams describe a highlight to create through the classic Readwise
+// API. Highlights are grouped into books by Title, Author and SourceURL, the
+// title is required.
+type HighlightParams struct {
+	Text   string `json:"text"`
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+	// One of books, articles, tweets or podcasts, articles when empty
+	Category  string `json:"category,omitempty"`
+	SourceURL string `json:"source_url,omitempty"`
+	// Identifier of the app creating the highlight
+	SourceType    string     `json:"source_type,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	Location      int        `json:"location,omitempty"`
+	LocationType  string     `json:"location_type,omitempty"`
+	HighlightedAt *time.Time `json:"highlighted_at,omitempty"`
+	HighlightURL  string     `json:"highlight_url,omitempty"`
+}
+
+// HighlightBook is a book that gained highlights.
+type HighlightBook struct {
+	ID    int
+	Title string
+	// IDs of the highlights created or changed in the book
+	HighlightIDs []int
+}
+
+type highlightBook struct {
+	ID                 int    `json:"id"`
+	Title              string `json:"title"`
+	ModifiedHighlights []int  `json:"modified_highlights"`
+}
+
+func (b highlightBook) toHighlightBook() HighlightBook {
+	return HighlightBook{
+		ID:           b.ID,
+		Title:        b.Title,
+		HighlightIDs: b.ModifiedHighlights,
+	}
+}
+
+// CreateHighlights creates highlights, or updates them where one with the same
+// text, title, author and source URL exists.
+func (c *Client) CreateHighlights(ctx context.Context, highlights ...HighlightParams) ([]HighlightBook, error) {
+	if len(highlights) == 0 {
+		return nil, errors.New("no highlights to create")
+	}
+
+	b, err := json.Marshal(map[string][]HighlightParams{"highlights": highlights})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.addrV2+"/highlights/", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, newAPIError(resp)
+	}
+
+	var hbs []highlightBook
+	if err := decodeBody(resp, &hbs); err != nil {
+		return nil, err
+	}
+
+	books := make([]HighlightBook, 0, len(hbs))
+	for _, hb := range hbs {
+		books = append(books, hb.toHighlightBook())
+	}
+
+	return books, nil
+}
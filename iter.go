@@ -0,0 +1,53 @@
+package readwisereader
+
+import (
+	"context"
+	"iter"
+)
+
+// Result carries one value yielded by an iterator along with its error.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// ToChannel drains seq into a channel, for code that can't range over
+// functions. The channel is closed once seq is exhausted, yields an error or
+// ctx is done; an error is always the last value sent.
+func ToChannel[T any](ctx context.Context, seq iter.Seq2[T, error]) <-chan Result[T] {
+	ch := make(chan Result[T])
+
+	go func() {
+		defer close(ch)
+
+		for v, err := range seq {
+			select {
+			case ch <- Result[T]{Value: v, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// ForEach calls fn for every value of seq, stopping at the first error
+// returned by either seq or fn.
+func ForEach[T any](seq iter.Seq2[T, error], fn func(T) error) error {
+	for v, err := range seq {
+		if err != nil {
+			return err
+		}
+
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
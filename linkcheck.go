@@ -0,0 +1,164 @@
+package readwisereader
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// LinkResult is the outcome of checking a document's source URL.
+type LinkResult struct {
+	Document Document
+	// Status code of the response, zero if there was none
+	StatusCode int
+	// Error encountered while checking, if any
+	Err error
+	// Whether the link is gone for good: 404, 410 or an unknown host
+	Dead bool
+}
+
+// LinkChecker checks whether the source URLs of documents still resolve.
+// The zero value is usable.
+type LinkChecker struct {
+	// Client used for the requests, defaults to one with a 30 second timeout
+	Client *http.Client
+	// Number of concurrent checks, defaults to 8
+	Workers int
+}
+
+// CheckLinks checks docs with a default LinkChecker.
+func CheckLinks(ctx context.Context, docs iter.Seq2[Document, error]) iter.Seq2[LinkResult, error] {
+	var lc LinkChecker
+	return lc.Check(ctx, docs)
+}
+
+// Check checks the source URL of every document in docs, skipping highlights,
+// notes and documents without an http(s) URL. Results are yielded as checks
+// complete, not in the order of docs. An error is only yielded when docs
+// yields one, failed checks are reported through LinkResult.Err.
+func (lc *LinkChecker) Check(ctx context.Context, docs iter.Seq2[Document, error]) iter.Seq2[LinkResult, error] {
+	client := lc.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	workers := lc.Workers
+	if workers <= 0 {
+		workers = 8
+	}
+
+	return func(yield func(LinkResult, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		jobs := make(chan Document)
+		results := make(chan LinkResult)
+		errc := make(chan error, 1)
+
+		go func() {
+			defer close(jobs)
+			for doc, err := range docs {
+				if err != nil {
+					errc <- err
+					return
+				}
+
+				if doc.ParentID != "" || !isHTTPURL(doc.SourceURL) {
+					continue
+				}
+
+				select {
+				case jobs <- doc:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for doc := range jobs {
+					select {
+					case results <- checkLink(ctx, client, doc):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		defer func() {
+			cancel()
+			for range results {
+			}
+		}()
+
+		for r := range results {
+			if !yield(r, nil) {
+				return
+			}
+		}
+
+		select {
+		case err := <-errc:
+			yield(LinkResult{}, err)
+		default:
+		}
+	}
+}
+
+func checkLink(ctx context.Context, client *http.Client, doc Document) LinkResult {
+	result := LinkResult{Document: doc}
+
+	status, err := requestStatus(ctx, client, "HEAD", doc.SourceURL)
+	// Plenty of servers don't implement HEAD properly, give them a GET.
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusForbidden || status == http.StatusNotImplemented) {
+		status, err = requestStatus(ctx, client, "GET", doc.SourceURL)
+	}
+
+	result.StatusCode = status
+	result.Err = err
+
+	var dnsErr *net.DNSError
+	switch {
+	case status == http.StatusNotFound, status == http.StatusGone:
+		result.Dead = true
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		result.Dead = true
+	}
+
+	return result
+}
+
+func requestStatus(ctx context.Context, client *http.Client, method, u string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	drainAndClose(resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+func isHTTPURL(u string) bool {
+	parsed, err := url.Parse(u)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
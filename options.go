@@ -0,0 +1,72 @@
+package readwisereader
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient makes the client send requests with hc, for custom or
+// instrumented transports. hc is copied, later changes to it have no effect.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.client = *hc
+	}
+}
+
+// WithBaseURL points the client at another host than https://readwise.io,
+// a local test server for example. Both the Reader and the classic Readwise
+// API are expected under it, at /api/v3 and /api/v2.
+func WithBaseURL(u string) Option {
+	u = strings.TrimSuffix(u, "/")
+	return func(c *Client) {
+		c.addr = u + "/api/v3"
+		c.addrV2 = u + "/api/v2"
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithTimeout bounds each HTTP request to d. It overrides the timeout of a
+// client given with WithHTTPClient.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithTokens makes the client rotate through tokens, see NewClientWithTokens.
+// It panics if no token is given.
+func WithTokens(tokens ...string) Option {
+	if len(tokens) == 0 {
+		panic("readwisereader: no tokens")
+	}
+
+	return func(c *Client) {
+		c.tokens = newTokenRing(tokens)
+	}
+}
+
+// WithLogger makes the client log every request and response to logger at
+// debug level. Tokens are never logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+func (c *Client) debug(ctx context.Context, msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.DebugContext(ctx, msg, args...)
+	}
+}
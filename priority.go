@@ -0,0 +1,170 @@
+package readwisereader
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority orders requests waiting for the client. When requests queue up,
+// because too many are in flight or the API asked the client to back off,
+// higher priorities go first and equal priorities go in arrival order.
+type Priority int
+
+const (
+	PriorityBackground  Priority = -1
+	PriorityNormal      Priority = 0
+	PriorityInteractive Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose requests are scheduled with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+
+	return PriorityNormal
+}
+
+// maxInFlight bounds concurrent requests per client.
+const maxInFlight = 4
+
+// scheduler hands out request slots by priority.
+type scheduler struct {
+	mu          sync.Mutex
+	free        int
+	pausedUntil time.Time
+	timer       *time.Timer
+	waiters     waiterHeap
+	seq         uint64
+}
+
+type waiter struct {
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+func newScheduler(slots int) *scheduler {
+	return &scheduler{free: slots}
+}
+
+// acquire blocks until the caller may send a request, which it must follow
+// with a call to release.
+func (s *scheduler) acquire(ctx context.Context) error {
+	s.mu.Lock()
+	if s.free > 0 && len(s.waiters) == 0 && !time.Now().Before(s.pausedUntil) {
+		s.free--
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.seq++
+	w := &waiter{
+		priority: priorityFrom(ctx),
+		seq:      s.seq,
+		ready:    make(chan struct{}),
+	}
+	heap.Push(&s.waiters, w)
+	s.dispatchLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// The slot may have been handed over while the context was being
+		// canceled, give it back.
+		if w.index < 0 {
+			s.free++
+			s.dispatchLocked()
+		} else {
+			heap.Remove(&s.waiters, w.index)
+		}
+
+		return ctx.Err()
+	}
+}
+
+func (s *scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.free++
+	s.dispatchLocked()
+}
+
+// pause holds back every request for d, used when the API rate limits us so
+// that the queue, not whoever retries first, decides who goes next.
+func (s *scheduler) pause(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(s.pausedUntil) {
+		s.pausedUntil = until
+	}
+}
+
+func (s *scheduler) dispatchLocked() {
+	if wait := time.Until(s.pausedUntil); wait > 0 {
+		if s.timer == nil {
+			s.timer = time.AfterFunc(wait, func() {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				s.timer = nil
+				s.dispatchLocked()
+			})
+		}
+		return
+	}
+
+	for s.free > 0 && len(s.waiters) > 0 {
+		w := heap.Pop(&s.waiters).(*waiter)
+		s.free--
+		close(w.ready)
+	}
+}
+
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
@@ -0,0 +1,175 @@
+package readwisereader
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimits are request budgets per minute. A zero budget is unlimited.
+type RateLimits struct {
+	// Saves and updates.
+	Writes int
+	// Every other request.
+	Reads int
+}
+
+// DefaultRateLimits are the limits Readwise enforces per token.
+var DefaultRateLimits = RateLimits{Writes: 50, Reads: 20}
+
+// WithRateLimit makes the client hold requests back so it stays within
+// limits instead of being rate limited by the API. The limits are shared by
+// every token the client rotates through, so they are conservative for
+// clients with several.
+func WithRateLimit(limits RateLimits) Option {
+	return func(c *Client) {
+		c.limiter = &rateLimiter{
+			writes: newBucket(limits.Writes),
+			reads:  newBucket(limits.Reads),
+		}
+	}
+}
+
+type rateLimiter struct {
+	writes, reads *bucket
+}
+
+// wait blocks until req fits the budget.
+func (l *rateLimiter) wait(ctx context.Context, req *http.Request) error {
+	if l == nil {
+		return nil
+	}
+
+	switch req.Method {
+	case http.MethodPost, http.MethodPatch:
+		return l.writes.wait(ctx)
+	default:
+		return l.reads.wait(ctx)
+	}
+}
+
+// bucket is a token bucket holding up to a minute's worth of requests.
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens a second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(perMinute int) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	return &bucket{
+		rate:   float64(perMinute) / 60,
+		burst:  float64(perMinute),
+		tokens: float64(perMinute),
+		last:   time.Now(),
+	}
+}
+
+func (b *bucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+	b.last = now
+
+	// Take the token right away, going into debt if need be, so that waiters
+	// are served in order.
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// RateLimitStatus is the budget the API reported with its last response.
+type RateLimitStatus struct {
+	// Requests allowed per window
+	Limit int
+	// Requests left in the current window
+	Remaining int
+	// When the window resets
+	Reset time.Time
+	// When the status was reported, zero if the API never reported any
+	ObservedAt time.Time
+}
+
+// RateLimitStatus returns the rate limit status the API reported last, for
+// long running jobs that want to pace themselves.
+func (c *Client) RateLimitStatus() RateLimitStatus {
+	c.rateStatus.mu.Lock()
+	defer c.rateStatus.mu.Unlock()
+
+	return c.rateStatus.status
+}
+
+type rateLimitState struct {
+	mu     sync.Mutex
+	status RateLimitStatus
+}
+
+// observe records the rate limit headers of resp. A 429 without them still
+// tells that nothing is left until Retry-After.
+func (s *rateLimitState) observe(resp *http.Response) {
+	now := time.Now()
+	h := resp.Header
+
+	status := RateLimitStatus{ObservedAt: now}
+	limit, errLimit := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	remaining, errRemaining := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	switch {
+	case errLimit == nil && errRemaining == nil:
+		status.Limit, status.Remaining = limit, remaining
+		if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			status.Reset = resetTime(now, reset)
+		}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		s.mu.Lock()
+		status.Limit = s.status.Limit
+		s.mu.Unlock()
+		if seconds, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+			status.Reset = now.Add(time.Duration(seconds) * time.Second)
+		}
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
+
+// resetTime reads a reset header, which APIs send either as a Unix time or as
+// seconds from now.
+func resetTime(now time.Time, v int64) time.Time {
+	const unixThreshold = 1 << 30
+	if v >= unixThreshold {
+		return time.Unix(v, 0)
+	}
+
+	return now.Add(time.Duration(v) * time.Second)
+}
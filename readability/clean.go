@@ -0,0 +1,301 @@
+package readability
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// trackers matches the hosts and paths of common analytics and tracking
+// resources.
+var trackers = regexp.MustCompile(`(?i)google-analytics\.com|googletagmanager\.com|doubleclick\.net|facebook\.com/tr|connect\.facebook\.net|scorecardresearch\.com|quantserve\.com|hotjar\.com|segment\.(com|io)|mixpanel\.com|list-manage\.com/track|/open\.php|/pixel|/track(ing)?/|/beacon`)
+
+// essentialStyles are the inline style properties kept by Clean, the ones
+// that carry meaning rather than looks.
+var essentialStyles = map[string]bool{
+	"text-align":      true,
+	"font-weight":     true,
+	"font-style":      true,
+	"text-decoration": true,
+	"vertical-align":  true,
+	"width":           true,
+	"height":          true,
+}
+
+// cssComments, simpleSelectors and selectorParts pick style sheets apart
+// for inlining.
+var (
+	cssComments     = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	simpleSelectors = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*)?((?:[.#][\w-]+)*)$`)
+	selectorParts   = regexp.MustCompile(`[.#][\w-]+`)
+)
+
+// cleanedTags are dropped along with their content by Clean.
+var cleanedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Link:     true,
+	atom.Base:     true,
+}
+
+// Clean tidies a whole page before it is handed to Reader: scripts, trackers
+// and event handlers go, and relative URLs are resolved against base, nil to
+// leave them alone. Style elements go too, once the few properties that
+// matter are inlined into the elements their rules select; only rules with
+// simple selectors, a tag, ID and classes, are inlined, in the order they
+// appear, and an element's own style attribute takes precedence. Style
+// attributes keep those properties only. Unlike Extract the page keeps its
+// structure.
+func Clean(r io.Reader, base *url.URL) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+
+	var rules []styleRule
+	for n := range doc.Descendants() {
+		if n.DataAtom == atom.Style && n.Type == html.ElementNode {
+			rules = append(rules, parseStyleRules(textContent(n))...)
+		}
+	}
+
+	var remove []*html.Node
+	for n := range doc.Descendants() {
+		switch n.Type {
+		case html.CommentNode:
+			remove = append(remove, n)
+		case html.ElementNode:
+			if cleanedTags[n.DataAtom] || isTracker(n) {
+				remove = append(remove, n)
+				continue
+			}
+			inlineStyles(n, rules)
+			cleanAttrs(n)
+		}
+	}
+
+	for _, n := range remove {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+
+	resolveURLs(doc, base)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// isTracker reports whether n loads a tracker or is a tracking pixel.
+func isTracker(n *html.Node) bool {
+	if n.DataAtom != atom.Img {
+		return false
+	}
+
+	if trackers.MatchString(attr(n, "src")) {
+		return true
+	}
+
+	w, h := attr(n, "width"), attr(n, "height")
+	return (w == "0" || w == "1") && (h == "0" || h == "1")
+}
+
+func cleanAttrs(n *html.Node) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		switch {
+		case strings.HasPrefix(key, "on"), strings.HasPrefix(key, "data-"):
+			continue
+		case (key == "href" || key == "src") && strings.HasPrefix(strings.ToLower(strings.TrimSpace(a.Val)), "javascript:"):
+			continue
+		case key == "style":
+			a.Val = essentialStyle(a.Val)
+			if a.Val == "" {
+				continue
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+}
+
+func essentialStyle(style string) string {
+	var kept []string
+	for _, d := range essentialDecls(style) {
+		kept = append(kept, d.prop+": "+d.value)
+	}
+
+	return strings.Join(kept, "; ")
+}
+
+type declaration struct {
+	prop, value string
+}
+
+// essentialDecls returns the declarations of essential properties in style.
+func essentialDecls(style string) []declaration {
+	var decls []declaration
+	for _, decl := range strings.Split(style, ";") {
+		prop, value, ok := strings.Cut(decl, ":")
+		prop = strings.ToLower(strings.TrimSpace(prop))
+		if ok && essentialStyles[prop] {
+			decls = append(decls, declaration{prop: prop, value: strings.TrimSpace(value)})
+		}
+	}
+
+	return decls
+}
+
+// styleRule holds the essential declarations of a style sheet rule.
+type styleRule struct {
+	selectors []simpleSelector
+	decls     []declaration
+}
+
+// simpleSelector matches elements by tag, ID and classes, any of which may be
+// empty.
+type simpleSelector struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// parseStyleRules returns the rules of a style sheet that have essential
+// declarations and at least one simple selector. At-rules, media queries
+// included, are skipped.
+func parseStyleRules(css string) []styleRule {
+	css = cssComments.ReplaceAllString(css, "")
+
+	var rules []styleRule
+	for len(css) > 0 {
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			break
+		}
+		prelude := strings.TrimSpace(css[:open])
+
+		// Find the end of the block, nested blocks of at-rules included.
+		end, depth := -1, 0
+		for i := open; i < len(css) && end < 0; i++ {
+			switch css[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+		body := css[open+1 : end]
+		css = css[end+1:]
+
+		if strings.HasPrefix(prelude, "@") || strings.Contains(body, "{") {
+			continue
+		}
+
+		var rule styleRule
+		for _, s := range strings.Split(prelude, ",") {
+			if sel, ok := parseSelector(strings.TrimSpace(s)); ok {
+				rule.selectors = append(rule.selectors, sel)
+			}
+		}
+		rule.decls = essentialDecls(body)
+		if len(rule.selectors) > 0 && len(rule.decls) > 0 {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+func parseSelector(s string) (simpleSelector, bool) {
+	m := simpleSelectors.FindStringSubmatch(s)
+	if s == "" || m == nil {
+		return simpleSelector{}, false
+	}
+
+	sel := simpleSelector{tag: strings.ToLower(m[1])}
+	for _, part := range selectorParts.FindAllString(m[2], -1) {
+		if part[0] == '#' {
+			sel.id = part[1:]
+		} else {
+			sel.classes = append(sel.classes, part[1:])
+		}
+	}
+
+	return sel, true
+}
+
+func (sel simpleSelector) matches(n *html.Node) bool {
+	if sel.tag != "" && sel.tag != n.Data {
+		return false
+	}
+	if sel.id != "" && sel.id != attr(n, "id") {
+		return false
+	}
+
+	classes := strings.Fields(attr(n, "class"))
+	for _, c := range sel.classes {
+		if !slices.Contains(classes, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inlineStyles sets the style attribute of n to the declarations of the
+// rules matching it, followed by its own.
+func inlineStyles(n *html.Node, rules []styleRule) {
+	var decls []declaration
+	for _, rule := range rules {
+		if slices.ContainsFunc(rule.selectors, func(sel simpleSelector) bool { return sel.matches(n) }) {
+			decls = append(decls, rule.decls...)
+		}
+	}
+	if len(decls) == 0 {
+		return
+	}
+
+	i := slices.IndexFunc(n.Attr, func(a html.Attribute) bool { return strings.EqualFold(a.Key, "style") })
+	if i < 0 {
+		n.Attr = append(n.Attr, html.Attribute{Key: "style"})
+		i = len(n.Attr) - 1
+	}
+	decls = append(decls, essentialDecls(n.Attr[i].Val)...)
+
+	// Later declarations of a property win, keep each in its first place.
+	values := map[string]string{}
+	var props []string
+	for _, d := range decls {
+		if _, ok := values[d.prop]; !ok {
+			props = append(props, d.prop)
+		}
+		values[d.prop] = d.value
+	}
+
+	var style []string
+	for _, prop := range props {
+		style = append(style, prop+": "+values[prop])
+	}
+	n.Attr[i].Val = strings.Join(style, "; ")
+}
@@ -0,0 +1,291 @@
+// Package readability extracts the main content of an HTML page, for
+// documents Reader failed to parse.
+//
+// The scoring follows the classic Arc90 readability heuristics: paragraphs
+// with plenty of text score their ancestors, link-heavy and boilerplate-looking
+// elements are penalized, and the best scoring element is the article.
+package readability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPageSize caps how much of a page Fetch reads.
+const maxPageSize = 10 << 20
+
+var ErrNoContent = errors.New("no readable content found")
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|header|menu|modal|nav|popup|promo|related|remark|share|shoutbox|sidebar|social|sponsor|subscribe|ad-break|agegate|pagination|pager`)
+	maybeCandidate     = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveNames      = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeNames      = regexp.MustCompile(`(?i)hidden|banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// removedTags never hold article content.
+var removedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Input:    true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Svg:      true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+	atom.Link:     true,
+	atom.Meta:     true,
+}
+
+// Fetch downloads the page at rawURL and extracts its content.
+func Fetch(ctx context.Context, client *http.Client, rawURL string) (string, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch %s: unexpected status code: %d", rawURL, resp.StatusCode)
+	}
+
+	return Extract(io.LimitReader(resp.Body, maxPageSize), base)
+}
+
+// Extract returns the main content of the HTML page read from r as an HTML
+// fragment. Relative links and images are resolved against base, if given.
+func Extract(r io.Reader, base *url.URL) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+
+	prune(doc)
+
+	scores := map[*html.Node]float64{}
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			continue
+		}
+
+		text := textContent(n)
+		if len(text) < 25 {
+			continue
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+
+		parent := n.Parent
+		if parent == nil || parent.Type != html.ElementNode {
+			continue
+		}
+		initScore(scores, parent)
+		scores[parent] += score
+
+		if grandparent := parent.Parent; grandparent != nil && grandparent.Type == html.ElementNode {
+			initScore(scores, grandparent)
+			scores[grandparent] += score / 2
+		}
+	}
+
+	var top *html.Node
+	var topScore float64
+	for n, score := range scores {
+		score *= 1 - linkDensity(n)
+		scores[n] = score
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+	}
+
+	if top == nil {
+		return "", ErrNoContent
+	}
+
+	// Content is often split across siblings, e.g. consecutive divs, pull in
+	// the ones that score close to the winner.
+	threshold := max(10, topScore*0.2)
+	var b strings.Builder
+	b.WriteString("<div>")
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling != top && scores[sibling] < threshold {
+			continue
+		}
+
+		resolveURLs(sibling, base)
+		if err := html.Render(&b, sibling); err != nil {
+			return "", err
+		}
+	}
+	b.WriteString("</div>")
+
+	return b.String(), nil
+}
+
+// prune removes elements that can't be content.
+func prune(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+
+		if c.Type == html.CommentNode || (c.Type == html.ElementNode && unlikely(c)) {
+			n.RemoveChild(c)
+		} else {
+			prune(c)
+		}
+
+		c = next
+	}
+}
+
+func unlikely(n *html.Node) bool {
+	if removedTags[n.DataAtom] {
+		return true
+	}
+
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		return false
+	}
+
+	names := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyCandidates.MatchString(names) && !maybeCandidate.MatchString(names)
+}
+
+func initScore(scores map[*html.Node]float64, n *html.Node) {
+	if _, ok := scores[n]; ok {
+		return
+	}
+
+	var score float64
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	names := attr(n, "class") + " " + attr(n, "id")
+	if positiveNames.MatchString(names) {
+		score += 25
+	}
+	if negativeNames.MatchString(names) {
+		score -= 25
+	}
+
+	scores[n] = score
+}
+
+// linkDensity is the share of text in n that is link text.
+func linkDensity(n *html.Node) float64 {
+	total := len(textContent(n))
+	if total == 0 {
+		return 0
+	}
+
+	var links int
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && d.DataAtom == atom.A {
+			links += len(textContent(d))
+		}
+	}
+
+	return float64(links) / float64(total)
+}
+
+func resolveURLs(n *html.Node, base *url.URL) {
+	if base == nil {
+		return
+	}
+
+	resolve := func(n *html.Node) {
+		for i, a := range n.Attr {
+			switch a.Key {
+			case "href", "src", "poster":
+				if u, err := base.Parse(a.Val); err == nil {
+					n.Attr[i].Val = u.String()
+				}
+			case "srcset":
+				n.Attr[i].Val = resolveSrcset(a.Val, base)
+			}
+		}
+	}
+
+	resolve(n)
+	for d := range n.Descendants() {
+		resolve(d)
+	}
+}
+
+// resolveSrcset resolves the URLs of a srcset, keeping their descriptors.
+func resolveSrcset(srcset string, base *url.URL) string {
+	candidates := strings.Split(srcset, ",")
+	for i, c := range candidates {
+		fields := strings.Fields(c)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if u, err := base.Parse(fields[0]); err == nil {
+			fields[0] = u.String()
+		}
+		candidates[i] = strings.Join(fields, " ")
+	}
+
+	return strings.Join(candidates, ", ")
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,139 @@
+// Package readwisereadermock provides a mock implementation of
+// readwisereader.ReaderAPI.
+package readwisereadermock
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// API implements readwisereader.ReaderAPI by calling the matching function
+// field. Methods whose field is nil are no-ops returning zero values, with
+// the exception of ListPaginate which falls back to paging through ListFunc,
+// and Get, Documents and SaveBatch which fall back to the methods they are
+// built on.
+type API struct {
+	ListFunc         func(ctx context.Context, params readwisereader.ListParams) (*readwisereader.ListResponse, error)
+	ListPaginateFunc func(ctx context.Context, params readwisereader.ListParams) iter.Seq2[readwisereader.Page, error]
+	SaveFunc         func(ctx context.Context, params readwisereader.SaveParams) (*readwisereader.SaveResponse, error)
+	UpdateFunc       func(ctx context.Context, ID string, params readwisereader.UpdateParams) (*readwisereader.UpdateResponse, error)
+	DeleteFunc       func(ctx context.Context, ID string) error
+	GetFunc          func(ctx context.Context, ID string, opts ...readwisereader.GetOption) (*readwisereader.Document, error)
+	DocumentsFunc    func(ctx context.Context, params readwisereader.ListParams) iter.Seq2[readwisereader.Document, error]
+	SaveBatchFunc    func(ctx context.Context, batch []readwisereader.SaveParams, opts ...readwisereader.SaveBatchOption) ([]readwisereader.SaveResult, error)
+}
+
+var _ readwisereader.ReaderAPI = (*API)(nil)
+
+func (m *API) List(ctx context.Context, params readwisereader.ListParams) (*readwisereader.ListResponse, error) {
+	if m.ListFunc == nil {
+		return &readwisereader.ListResponse{}, nil
+	}
+
+	return m.ListFunc(ctx, params)
+}
+
+func (m *API) ListPaginate(ctx context.Context, params readwisereader.ListParams) iter.Seq2[readwisereader.Page, error] {
+	if m.ListPaginateFunc != nil {
+		return m.ListPaginateFunc(ctx, params)
+	}
+
+	return func(yield func(readwisereader.Page, error) bool) {
+		for index := 0; ; index++ {
+			resp, err := m.List(ctx, params)
+			if err != nil {
+				yield(readwisereader.Page{}, err)
+				return
+			}
+
+			resp.Index = index
+			if !yield(resp.Page, nil) || resp.NextPageCursor == "" {
+				return
+			}
+
+			params.PageCursor = resp.NextPageCursor
+		}
+	}
+}
+
+func (m *API) Save(ctx context.Context, params readwisereader.SaveParams) (*readwisereader.SaveResponse, error) {
+	if m.SaveFunc == nil {
+		return &readwisereader.SaveResponse{}, nil
+	}
+
+	return m.SaveFunc(ctx, params)
+}
+
+func (m *API) Update(ctx context.Context, ID string, params readwisereader.UpdateParams) (*readwisereader.UpdateResponse, error) {
+	if m.UpdateFunc == nil {
+		return &readwisereader.UpdateResponse{ID: ID}, nil
+	}
+
+	return m.UpdateFunc(ctx, ID, params)
+}
+
+func (m *API) Delete(ctx context.Context, ID string) error {
+	if m.DeleteFunc == nil {
+		return nil
+	}
+
+	return m.DeleteFunc(ctx, ID)
+}
+
+// Get falls back to looking the ID up with List, ignoring opts.
+func (m *API) Get(ctx context.Context, ID string, opts ...readwisereader.GetOption) (*readwisereader.Document, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, ID, opts...)
+	}
+
+	resp, err := m.List(ctx, readwisereader.ListParams{ID: ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Results) == 0 {
+		return nil, fmt.Errorf("document %s: %w", ID, readwisereader.ErrNotFound)
+	}
+
+	return &resp.Results[0], nil
+}
+
+func (m *API) Documents(ctx context.Context, params readwisereader.ListParams) iter.Seq2[readwisereader.Document, error] {
+	if m.DocumentsFunc != nil {
+		return m.DocumentsFunc(ctx, params)
+	}
+
+	return func(yield func(readwisereader.Document, error) bool) {
+		for page, err := range m.ListPaginate(ctx, params) {
+			if err != nil {
+				yield(readwisereader.Document{}, err)
+				return
+			}
+
+			for _, doc := range page.Results {
+				if !yield(doc, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SaveBatch falls back to calling Save for each document in turn, options are
+// ignored.
+func (m *API) SaveBatch(ctx context.Context, batch []readwisereader.SaveParams, opts ...readwisereader.SaveBatchOption) ([]readwisereader.SaveResult, error) {
+	if m.SaveBatchFunc != nil {
+		return m.SaveBatchFunc(ctx, batch, opts...)
+	}
+
+	results := make([]readwisereader.SaveResult, 0, len(batch))
+	for _, params := range batch {
+		resp, err := m.Save(ctx, params)
+		results = append(results, readwisereader.SaveResult{Params: params, Response: resp, Err: err})
+	}
+
+	return results, ctx.Err()
+}
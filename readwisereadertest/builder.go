@@ -0,0 +1,149 @@
+// Package readwisereadertest provides helpers for testing code built on
+// readwisereader: document builders, canned API responses and a fake API
+// server.
+package readwisereadertest
+
+import (
+	"fmt"
+	"maps"
+	"sync/atomic"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+var nextID atomic.Int64
+
+// DocumentBuilder builds a readwisereader.Document with realistic defaults.
+type DocumentBuilder struct {
+	doc readwisereader.Document
+}
+
+// NewDocument returns a builder for a new article with a unique ID.
+func NewDocument() *DocumentBuilder {
+	id := fmt.Sprintf("01test%019d", nextID.Add(1))
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return &DocumentBuilder{
+		doc: readwisereader.Document{
+			ID:        id,
+			URL:       "https://read.readwise.io/read/" + id,
+			SourceURL: "https://example.com/" + id,
+			Title:     "Document " + id,
+			Source:    "reader-web",
+			Category:  readwisereader.CategoryArticle,
+			Location:  readwisereader.LocationNew,
+			Tags:      map[string]readwisereader.Tag{},
+			SiteName:  "example.com",
+			WordCount: 1000,
+			CreatedAt: now,
+			UpdatedAt: now,
+			SavedAt:   now,
+		},
+	}
+}
+
+func (b *DocumentBuilder) WithID(id string) *DocumentBuilder {
+	b.doc.ID = id
+	b.doc.URL = "https://read.readwise.io/read/" + id
+	return b
+}
+
+func (b *DocumentBuilder) WithTitle(title string) *DocumentBuilder {
+	b.doc.Title = title
+	return b
+}
+
+func (b *DocumentBuilder) WithAuthor(author string) *DocumentBuilder {
+	b.doc.Author = author
+	return b
+}
+
+func (b *DocumentBuilder) WithSourceURL(u string) *DocumentBuilder {
+	b.doc.SourceURL = u
+	return b
+}
+
+func (b *DocumentBuilder) WithCategory(category readwisereader.Category) *DocumentBuilder {
+	b.doc.Category = category
+	return b
+}
+
+func (b *DocumentBuilder) WithLocation(location readwisereader.Location) *DocumentBuilder {
+	b.doc.Location = location
+	return b
+}
+
+// WithTags sets the tags in the shape the API returns them.
+func (b *DocumentBuilder) WithTags(names ...string) *DocumentBuilder {
+	b.doc.Tags = make(map[string]readwisereader.Tag, len(names))
+	b.doc.RawTags = make(map[string]any, len(names))
+	for _, name := range names {
+		b.doc.Tags[name] = readwisereader.Tag{
+			Name:    name,
+			Type:    "manual",
+			Created: time.UnixMilli(b.doc.CreatedAt.UnixMilli()),
+		}
+		b.doc.RawTags[name] = map[string]any{
+			"name":    name,
+			"type":    "manual",
+			"created": float64(b.doc.CreatedAt.UnixMilli()),
+		}
+	}
+	return b
+}
+
+func (b *DocumentBuilder) WithWordCount(n int) *DocumentBuilder {
+	b.doc.WordCount = n
+	return b
+}
+
+func (b *DocumentBuilder) WithReadingProgress(progress float64) *DocumentBuilder {
+	b.doc.ReadingProgress = progress
+	return b
+}
+
+func (b *DocumentBuilder) WithContent(content string) *DocumentBuilder {
+	b.doc.Content = content
+	return b
+}
+
+func (b *DocumentBuilder) WithHTMLContent(html string) *DocumentBuilder {
+	b.doc.HTMLContent = html
+	return b
+}
+
+// WithParent makes the document a highlight or note of parent.
+func (b *DocumentBuilder) WithParent(parent readwisereader.Document) *DocumentBuilder {
+	b.doc.ParentID = parent.ID
+	return b
+}
+
+// WithTimes sets when the document was created and last updated.
+func (b *DocumentBuilder) WithTimes(created, updated time.Time) *DocumentBuilder {
+	b.doc.CreatedAt = created
+	b.doc.SavedAt = created
+	b.doc.UpdatedAt = updated
+	return b
+}
+
+func (b *DocumentBuilder) Build() readwisereader.Document {
+	doc := b.doc
+	tags := make(map[string]readwisereader.Tag, len(doc.Tags))
+	for k, v := range doc.Tags {
+		tags[k] = v
+	}
+	doc.Tags = tags
+	doc.RawTags = maps.Clone(doc.RawTags)
+	return doc
+}
+
+// ListResponse wraps docs in a single, final page response.
+func ListResponse(docs ...readwisereader.Document) *readwisereader.ListResponse {
+	return &readwisereader.ListResponse{
+		Page: readwisereader.Page{
+			Count:   len(docs),
+			Results: docs,
+		},
+	}
+}
@@ -0,0 +1,14 @@
+package readwisereadertest
+
+import _ "embed"
+
+// Canned API responses, shaped like what Reader actually returns, nulls
+// included.
+var (
+	//go:embed fixtures/list.json
+	ListJSON []byte
+	//go:embed fixtures/save.json
+	SaveJSON []byte
+	//go:embed fixtures/update.json
+	UpdateJSON []byte
+)
@@ -0,0 +1,494 @@
+package readwisereadertest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// defaultPageSize is the page size of the list API.
+const defaultPageSize = 100
+
+// Server is a fake Reader API for integration tests. It keeps documents in
+// memory and implements listing with pagination and filters, saving with
+// deduplication by URL, updating, deleting and token validation. Lists are
+// ordered by updated_at, oldest first unless NewestFirst is set.
+type Server struct {
+	*httptest.Server
+
+	// Token is the only token accepted when set, any token is otherwise.
+	Token string
+	// PageSize bounds list pages, 100 like the API when zero.
+	PageSize int
+	// NewestFirst lists the most recently updated documents first, the API
+	// doesn't promise an order.
+	NewestFirst bool
+
+	mu       sync.Mutex
+	docs     map[string]readwisereader.Document
+	last     time.Time
+	requests int
+
+	limit       int
+	window      time.Duration
+	windowStart time.Time
+	windowCount int
+}
+
+// NewServer starts a fake API holding docs. Close it when done.
+func NewServer(docs ...readwisereader.Document) *Server {
+	s := &Server{docs: map[string]readwisereader.Document{}}
+	s.Add(docs...)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/list", s.list)
+	mux.HandleFunc("GET /api/v3/list/", s.list)
+	mux.HandleFunc("POST /api/v3/save", s.save)
+	mux.HandleFunc("POST /api/v3/save/", s.save)
+	mux.HandleFunc("PATCH /api/v3/update/{id}/", s.update)
+	mux.HandleFunc("DELETE /api/v3/delete/{id}", s.delete)
+	mux.HandleFunc("DELETE /api/v3/delete/{id}/", s.delete)
+	mux.HandleFunc("GET /api/v2/auth/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.Server = httptest.NewServer(s.guard(mux))
+	return s
+}
+
+// Client returns a client talking to the server.
+func (s *Server) Client(opts ...readwisereader.Option) *readwisereader.Client {
+	token := s.Token
+	if token == "" {
+		token = "test"
+	}
+
+	opts = append([]readwisereader.Option{
+		readwisereader.WithBaseURL(s.URL),
+		readwisereader.WithHTTPClient(s.Server.Client()),
+	}, opts...)
+
+	return readwisereader.NewClient(token, opts...)
+}
+
+// Add stores docs, replacing documents with the same ID.
+func (s *Server) Add(docs ...readwisereader.Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, doc := range docs {
+		s.docs[doc.ID] = doc
+	}
+}
+
+// Documents returns the stored documents in list order.
+func (s *Server) Documents() []readwisereader.Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedLocked()
+}
+
+// Requests returns how many requests the server has received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// SetRateLimit makes the server answer with 429 once more than requests
+// arrive within a window, like the API does, and report the budget left in
+// X-RateLimit headers. Zero requests disables it.
+func (s *Server) SetRateLimit(requests int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limit, s.window = requests, window
+	s.windowStart, s.windowCount = time.Time{}, 0
+}
+
+// guard counts requests and applies the token check and rate limit.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+
+		if s.Token != "" && r.Header.Get("Authorization") != "Token "+s.Token {
+			s.mu.Unlock()
+			writeError(w, http.StatusUnauthorized, "Invalid token.")
+			return
+		}
+
+		if s.limit > 0 {
+			now := time.Now()
+			if now.Sub(s.windowStart) >= s.window {
+				s.windowStart, s.windowCount = now, 0
+			}
+
+			s.windowCount++
+			reset := int(math.Ceil((s.window - now.Sub(s.windowStart)).Seconds()))
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(max(s.limit-s.windowCount, 0)))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(reset))
+			if s.windowCount > s.limit {
+				s.mu.Unlock()
+				w.Header().Set("Retry-After", strconv.Itoa(reset))
+				writeError(w, http.StatusTooManyRequests, "Request was throttled.")
+				return
+			}
+		}
+		s.mu.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var updatedAfter time.Time
+	if v := q.Get("updatedAfter"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid updatedAfter.")
+			return
+		}
+		updatedAfter = t
+	}
+
+	offset := 0
+	if v := q.Get("pageCursor"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "Invalid pageCursor.")
+			return
+		}
+		offset = n
+	}
+
+	s.mu.Lock()
+	var matched []readwisereader.Document
+	for _, doc := range s.sortedLocked() {
+		switch {
+		case q.Get("id") != "" && doc.ID != q.Get("id"):
+		case !updatedAfter.IsZero() && !doc.UpdatedAt.After(updatedAfter):
+		case q.Get("location") != "" && string(doc.Location) != q.Get("location"):
+		case q.Get("category") != "" && string(doc.Category) != q.Get("category"):
+		default:
+			matched = append(matched, doc)
+		}
+	}
+	s.mu.Unlock()
+
+	if offset > len(matched) {
+		writeError(w, http.StatusBadRequest, "Invalid pageCursor.")
+		return
+	}
+
+	size := cmp.Or(s.PageSize, defaultPageSize)
+	end := min(offset+size, len(matched))
+
+	resp := listResponse{Count: len(matched), Results: []apiDocument{}}
+	if end < len(matched) {
+		next := strconv.Itoa(end)
+		resp.NextPageCursor = &next
+	}
+
+	withHTML := q.Get("withHTMLContent") == "true"
+	for _, doc := range matched[offset:end] {
+		resp.Results = append(resp.Results, toAPIDocument(doc, withHTML))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) save(w http.ResponseWriter, r *http.Request) {
+	var params readwisereader.SaveParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.URL == "" {
+		writeError(w, http.StatusBadRequest, "Invalid document.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, doc := range s.docs {
+		if doc.ParentID == "" && doc.HasURL(params.URL) {
+			writeJSON(w, http.StatusOK, saveResponse{ID: doc.ID, URL: doc.URL})
+			return
+		}
+	}
+
+	now := s.nowLocked()
+	b := NewDocument().
+		WithSourceURL(params.URL).
+		WithTimes(now, now).
+		WithLocation(cmp.Or(params.Location, readwisereader.Location(readwisereader.LocationNew))).
+		WithCategory(cmp.Or(params.Category, readwisereader.Category(readwisereader.CategoryArticle))).
+		WithTags(params.Tags...)
+	if params.HTML != nil {
+		b.WithHTMLContent(*params.HTML)
+	}
+
+	doc := b.Build()
+	doc.LastMovedAt = now
+	doc.Title = valueOr(params.Title, params.URL)
+	doc.Author = valueOr(params.Author, "")
+	doc.Summary = valueOr(params.Summary, "")
+	doc.ImageURL = valueOr(params.ImageURL, "")
+	doc.Notes = valueOr(params.Notes, "")
+	if params.PublishedDate != nil {
+		doc.PublishedDate = *params.PublishedDate
+	}
+
+	s.docs[doc.ID] = doc
+	writeJSON(w, http.StatusCreated, saveResponse{ID: doc.ID, URL: doc.URL})
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid update.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.docs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	for field, raw := range fields {
+		if err := applyUpdate(&doc, readwisereader.UpdateField(field), raw); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string][]string{field: {err.Error()}})
+			return
+		}
+	}
+
+	doc.UpdatedAt = s.nowLocked()
+	if _, ok := fields[string(readwisereader.UpdateLocation)]; ok {
+		doc.LastMovedAt = doc.UpdatedAt
+	}
+	s.docs[doc.ID] = doc
+	writeJSON(w, http.StatusOK, saveResponse{ID: doc.ID, URL: doc.URL})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.docs[id]; !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	delete(s.docs, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func applyUpdate(doc *readwisereader.Document, field readwisereader.UpdateField, raw json.RawMessage) error {
+	var err error
+	switch field {
+	case readwisereader.UpdateTitle:
+		err = json.Unmarshal(raw, &doc.Title)
+	case readwisereader.UpdateAuthor:
+		err = json.Unmarshal(raw, &doc.Author)
+	case readwisereader.UpdateSummary:
+		err = json.Unmarshal(raw, &doc.Summary)
+	case readwisereader.UpdateImageURL:
+		err = json.Unmarshal(raw, &doc.ImageURL)
+	case readwisereader.UpdateNotes:
+		err = json.Unmarshal(raw, &doc.Notes)
+	case readwisereader.UpdateLocation:
+		err = json.Unmarshal(raw, &doc.Location)
+	case readwisereader.UpdateCategory:
+		err = json.Unmarshal(raw, &doc.Category)
+	case readwisereader.UpdateReadingProgress:
+		err = json.Unmarshal(raw, &doc.ReadingProgress)
+	case readwisereader.UpdatePublishedDate:
+		var t *time.Time
+		err = json.Unmarshal(raw, &t)
+		doc.PublishedDate = time.Time{}
+		if t != nil {
+			doc.PublishedDate = *t
+		}
+	case readwisereader.UpdateTags:
+		var names []string
+		if err = json.Unmarshal(raw, &names); err == nil {
+			tagged := NewDocument().WithTimes(time.Now(), time.Now()).WithTags(names...).Build()
+			doc.Tags, doc.RawTags = tagged.Tags, tagged.RawTags
+		}
+	default:
+		return fmt.Errorf("unknown field")
+	}
+
+	return err
+}
+
+// nowLocked returns the current time, moved past the last update so that
+// every change gets a distinct updated_at.
+func (s *Server) nowLocked() time.Time {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if !now.After(s.last) {
+		now = s.last.Add(time.Microsecond)
+	}
+	s.last = now
+
+	return now
+}
+
+func (s *Server) sortedLocked() []readwisereader.Document {
+	docs := make([]readwisereader.Document, 0, len(s.docs))
+	for _, doc := range s.docs {
+		docs = append(docs, doc)
+	}
+
+	slices.SortFunc(docs, func(a, b readwisereader.Document) int {
+		return cmp.Or(a.UpdatedAt.Compare(b.UpdatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	if s.NewestFirst {
+		slices.Reverse(docs)
+	}
+
+	return docs
+}
+
+type listResponse struct {
+	Count          int           `json:"count"`
+	NextPageCursor *string       `json:"nextPageCursor"`
+	Results        []apiDocument `json:"results"`
+}
+
+type saveResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// apiDocument is a document in the shape the API returns it.
+type apiDocument struct {
+	ID              string         `json:"id"`
+	URL             string         `json:"url"`
+	SourceURL       *string        `json:"source_url"`
+	Title           *string        `json:"title"`
+	Author          *string        `json:"author"`
+	Source          *string        `json:"source"`
+	Category        string         `json:"category"`
+	Location        *string        `json:"location"`
+	Tags            map[string]any `json:"tags"`
+	SiteName        *string        `json:"site_name"`
+	WordCount       *int           `json:"word_count"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	PublishedDate   *string        `json:"published_date"`
+	Notes           string         `json:"notes"`
+	Summary         *string        `json:"summary"`
+	ImageURL        *string        `json:"image_url"`
+	Content         *string        `json:"content"`
+	HTMLContent     *string        `json:"html_content,omitempty"`
+	ParentID        *string        `json:"parent_id"`
+	ReadingProgress float64        `json:"reading_progress"`
+	FirstOpenedAt   *time.Time     `json:"first_opened_at"`
+	LastOpenedAt    *time.Time     `json:"last_opened_at"`
+	SavedAt         time.Time      `json:"saved_at"`
+	LastMovedAt     time.Time      `json:"last_moved_at"`
+}
+
+func toAPIDocument(doc readwisereader.Document, withHTML bool) apiDocument {
+	tags := doc.RawTags
+	if tags == nil {
+		tags = make(map[string]any, len(doc.Tags))
+		for key, tag := range doc.Tags {
+			tags[key] = map[string]any{
+				"name":    tag.Name,
+				"type":    tag.Type,
+				"created": tag.Created.UnixMilli(),
+			}
+		}
+	}
+
+	d := apiDocument{
+		ID:              doc.ID,
+		URL:             doc.URL,
+		SourceURL:       nullable(doc.SourceURL),
+		Title:           nullable(doc.Title),
+		Author:          nullable(doc.Author),
+		Source:          nullable(doc.Source),
+		Category:        string(doc.Category),
+		Location:        nullable(string(doc.Location)),
+		Tags:            tags,
+		SiteName:        nullable(doc.SiteName),
+		WordCount:       nullable(doc.WordCount),
+		CreatedAt:       doc.CreatedAt,
+		UpdatedAt:       doc.UpdatedAt,
+		Notes:           doc.Notes,
+		Summary:         nullable(doc.Summary),
+		ImageURL:        nullable(doc.ImageURL),
+		Content:         nullable(doc.Content),
+		ParentID:        nullable(doc.ParentID),
+		ReadingProgress: doc.ReadingProgress,
+		FirstOpenedAt:   nullableTime(doc.FirstOpenedAt),
+		LastOpenedAt:    nullableTime(doc.LastOpenedAt),
+		SavedAt:         doc.SavedAt,
+		LastMovedAt:     doc.LastMovedAt,
+	}
+
+	if !doc.PublishedDate.IsZero() {
+		date := doc.PublishedDate.Format(time.DateOnly)
+		d.PublishedDate = &date
+	}
+
+	if withHTML {
+		d.HTMLContent = nullable(doc.HTMLContent)
+	}
+
+	return d
+}
+
+func nullable[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+
+	return &v
+}
+
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func valueOr[T any](p *T, fallback T) T {
+	if p == nil {
+		return fallback
+	}
+
+	return *p
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]string{"detail": detail})
+}
@@ -0,0 +1,113 @@
+package readwisereader
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides which failed requests are retried and how long to wait
+// in between. The zero value never retries.
+type RetryPolicy struct {
+	// MaxAttempts bounds the attempts at a request, the first one included.
+	MaxAttempts int
+	// The wait after the first failure, doubled after every further one up
+	// to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter is the fraction, between 0 and 1, by which a wait is randomly
+	// shortened so that clients failing together don't retry together.
+	Jitter float64
+	// StatusCodes are the response status codes worth retrying. Network
+	// errors are always retried.
+	StatusCodes []int
+}
+
+// DefaultRetryPolicy retries rate limits, server errors and network errors
+// a few times.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Jitter:         0.5,
+	StatusCodes: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// WithRetry makes every request the client sends retry transient failures
+// according to p, honoring Retry-After when the response has one. Requests
+// are not retried by default.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+type waitOutKey struct{}
+
+type waitOut struct {
+	onWait func(wait time.Duration)
+}
+
+// WaitOutRateLimits returns a context whose requests wait out rate limits
+// however often the API reports them, instead of failing with
+// ErrorRateLimited once the client's RetryPolicy gives up. Other failures
+// are retried as the policy says. ListPaginate, ExportPaginate, GetMany and
+// SaveBatch wait out rate limits this way.
+func WaitOutRateLimits(ctx context.Context) context.Context {
+	return waitOutRateLimits(ctx, nil)
+}
+
+// waitOutRateLimits is WaitOutRateLimits calling onWait, unless nil, before
+// every wait.
+func waitOutRateLimits(ctx context.Context, onWait func(wait time.Duration)) context.Context {
+	return context.WithValue(ctx, waitOutKey{}, waitOut{onWait: onWait})
+}
+
+// backoff reports whether attempt, which ended with resp or err, should be
+// retried and how long to wait before doing so.
+func (p RetryPolicy) backoff(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	var rle *ErrorRateLimited
+	var ue *url.Error
+	switch {
+	case errors.As(err, &rle):
+		if !slices.Contains(p.StatusCodes, http.StatusTooManyRequests) {
+			return 0, false
+		}
+		return rle.RetryAfter, true
+	case errors.As(err, &ue):
+	case err != nil:
+		return 0, false
+	case !slices.Contains(p.StatusCodes, resp.StatusCode):
+		return 0, false
+	default:
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	wait := p.InitialBackoff << (attempt - 1)
+	if p.MaxBackoff > 0 && (wait > p.MaxBackoff || wait <= 0) {
+		wait = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		wait -= time.Duration(rand.Float64() * min(p.Jitter, 1) * float64(wait))
+	}
+
+	return wait, true
+}
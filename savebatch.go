@@ -0,0 +1,99 @@
+package readwisereader
+
+import (
+	"context"
+	"sync"
+)
+
+// SaveResult is the outcome of one save of SaveBatch.
+type SaveResult struct {
+	Params   SaveParams
+	Response *SaveResponse
+	Err      error
+}
+
+// SaveBatchOption configures SaveBatch.
+type SaveBatchOption func(*saveBatchOptions)
+
+type saveBatchOptions struct {
+	onSaved func(SaveResult)
+}
+
+// OnSaved makes SaveBatch call fn with the result of each save as soon as it
+// is done, one call at a time, for reporting progress.
+func OnSaved(fn func(SaveResult)) SaveBatchOption {
+	return func(o *saveBatchOptions) {
+		o.onSaved = fn
+	}
+}
+
+// saveBatchConcurrency bounds the saves SaveBatch runs at once, the
+// scheduler bounds requests further.
+const saveBatchConcurrency = maxInFlight
+
+// SaveBatch saves every document of batch, running several saves at once
+// while keeping within the save rate limit, and waiting out rate limits the
+// API reports anyway. A client configured WithRateLimit is paced by its own
+// limits, any other by DefaultRateLimits. The results are in the order of
+// batch, saves not reached before ctx ends fail with its error. The error is
+// only non-nil if ctx ends before every document was saved.
+func (c *Client) SaveBatch(ctx context.Context, batch []SaveParams, opts ...SaveBatchOption) ([]SaveResult, error) {
+	var o saveBatchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	results := make([]SaveResult, len(batch))
+	for i, params := range batch {
+		results[i].Params = params
+	}
+
+	var limit *bucket
+	if c.limiter == nil {
+		limit = newBucket(DefaultRateLimits.Writes)
+	}
+
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range min(saveBatchConcurrency, len(batch)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Response, results[i].Err = c.saveWaiting(ctx, limit, batch[i])
+				if o.onSaved != nil {
+					mu.Lock()
+					o.onSaved(results[i])
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	fed := 0
+feed:
+	for ; fed < len(batch); fed++ {
+		select {
+		case jobs <- fed:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := fed; i < len(batch); i++ {
+		results[i].Err = ctx.Err()
+	}
+
+	return results, ctx.Err()
+}
+
+func (c *Client) saveWaiting(ctx context.Context, limit *bucket, params SaveParams) (*SaveResponse, error) {
+	if err := limit.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.Save(WaitOutRateLimits(ctx), params)
+}
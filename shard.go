@@ -0,0 +1,76 @@
+package readwisereader
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// ListSharded lists the documents matching params, like ListPaginate, but
+// splits the listing into shards covering consecutive windows of update
+// times and fetches them concurrently, the client's scheduler keeps the
+// shards within the rate limit. Each document is yielded once, in no
+// particular order.
+//
+// The API has no upper bound on update times, so every shard but the last
+// pages through everything updated after its window starts and skips the
+// documents past it; later shards have less to page through. The windows
+// are evenly sized between the oldest update of the first page and now,
+// documents updated while listing end up in the last shard. The first page
+// is fetched once, the first shard carries on after it.
+func (c *Client) ListSharded(ctx context.Context, params ListParams, shards int) iter.Seq2[Document, error] {
+	return func(yield func(Document, error) bool) {
+		params.PageCursor = ""
+
+		first, err := c.listPage(waitOutRateLimits(ctx, params.OnRateLimited), params, false)
+		if err != nil {
+			yield(Document{}, err)
+			return
+		}
+
+		listings := []listing{{params: params}}
+		if shards > 1 && len(first.Results) > 0 && len(first.Results) < first.Count {
+			oldest := first.Results[0].UpdatedAt
+			for _, doc := range first.Results {
+				if doc.UpdatedAt.Before(oldest) {
+					oldest = doc.UpdatedAt
+				}
+			}
+
+			listings = shardListings(params, oldest, time.Now(), shards)
+		}
+		listings[0].first = first
+
+		for doc, err := range c.mergeListings(ctx, listings) {
+			if !yield(doc, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// shardListings splits listing params into shards windows between from and
+// to. The last window is left open ended.
+func shardListings(params ListParams, from, to time.Time, shards int) []listing {
+	width := to.Sub(from) / time.Duration(shards)
+	if width <= 0 {
+		return []listing{{params: params}}
+	}
+
+	listings := make([]listing, 0, shards)
+	for i := range shards {
+		l := listing{params: params}
+		start := from.Add(time.Duration(i) * width)
+		if i > 0 {
+			// updatedAfter is exclusive, step back so that documents
+			// right at the boundary aren't lost; duplicates are dropped.
+			l.params.UpdatedAfter = start.Add(-time.Millisecond)
+		}
+		if i < shards-1 {
+			l.before = start.Add(width)
+		}
+		listings = append(listings, l)
+	}
+
+	return listings
+}
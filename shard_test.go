@@ -0,0 +1,45 @@
+package readwisereader_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/readwisereadertest"
+)
+
+func TestListSharded(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	var docs []readwisereader.Document
+	var want []string
+	for i := range 95 {
+		updated := now.Add(-time.Duration(i) * time.Hour)
+		doc := readwisereadertest.NewDocument().WithTimes(updated, updated).Build()
+		docs = append(docs, doc)
+		want = append(want, doc.ID)
+	}
+	slices.Sort(want)
+
+	for _, newestFirst := range []bool{false, true} {
+		srv := readwisereadertest.NewServer(docs...)
+		defer srv.Close()
+		srv.PageSize = 10
+		srv.NewestFirst = newestFirst
+
+		var got []string
+		for doc, err := range srv.Client().ListSharded(context.Background(), readwisereader.ListParams{}, 4) {
+			if err != nil {
+				t.Fatalf("newest first %t: %v", newestFirst, err)
+			}
+			got = append(got, doc.ID)
+		}
+		slices.Sort(got)
+
+		if !slices.Equal(got, want) {
+			t.Errorf("newest first %t: listed %d documents, want %d", newestFirst, len(got), len(want))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package readwisereader
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Snapshot is a self-contained copy of a document, enough to save it again
+// after it has been deleted.
+type Snapshot struct {
+	Document Document
+	TakenAt  time.Time
+}
+
+// Snapshot fetches the document with the given ID along with its HTML content.
+func (c *Client) Snapshot(ctx context.Context, ID string) (*Snapshot, error) {
+	doc, err := c.Get(ctx, ID, IncludeHTMLContent())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Document: *doc,
+		TakenAt:  time.Now(),
+	}, nil
+}
+
+// Restore saves the snapshotted document again. Reader assigns the restored
+// document a new ID; reading progress and highlights are not restored.
+func (c *Client) Restore(ctx context.Context, snapshot Snapshot) (*SaveResponse, error) {
+	return c.Save(ctx, snapshot.saveParams())
+}
+
+func (s Snapshot) saveParams() SaveParams {
+	doc := s.Document
+
+	params := SaveParams{
+		URL:      doc.SourceURL,
+		Location: doc.Location,
+		Category: doc.Category,
+	}
+	// Tags are keyed by their normalized name, save them by the name the
+	// user gave them.
+	for _, tag := range doc.Tags {
+		params.Tags = append(params.Tags, tag.Name)
+	}
+	slices.Sort(params.Tags)
+	if params.URL == "" {
+		params.URL = doc.URL
+	}
+
+	optional := func(v string) *string {
+		if v == "" {
+			return nil
+		}
+		return &v
+	}
+
+	params.HTML = optional(doc.HTMLContent)
+	params.Title = optional(doc.Title)
+	params.Author = optional(doc.Author)
+	params.Summary = optional(doc.Summary)
+	params.ImageURL = optional(doc.ImageURL)
+	params.Notes = optional(doc.Notes)
+
+	if params.HTML != nil {
+		// The content is already what Reader rendered, don't clean it twice.
+		shouldClean := false
+		params.ShouldCleanHTML = &shouldClean
+	}
+
+	if !doc.PublishedDate.IsZero() {
+		published := doc.PublishedDate
+		params.PublishedDate = &published
+	}
+
+	return params
+}
@@ -0,0 +1,41 @@
+package readwisereader
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Stats counts the requests a Client made since it was created.
+type Stats struct {
+	// Requests sent, retries included
+	Requests uint64
+	// Requests that failed or were answered with an error status
+	Errors uint64
+	// Requests answered with 429 Too Many Requests
+	RateLimited uint64
+}
+
+type clientStats struct {
+	requests    atomic.Uint64
+	errors      atomic.Uint64
+	rateLimited atomic.Uint64
+}
+
+// Stats returns the counters of the requests made so far.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests:    c.stats.requests.Load(),
+		Errors:      c.stats.errors.Load(),
+		RateLimited: c.stats.rateLimited.Load(),
+	}
+}
+
+func (s *clientStats) record(status int, err error) {
+	s.requests.Add(1)
+	if err != nil || status >= http.StatusBadRequest {
+		s.errors.Add(1)
+	}
+	if status == http.StatusTooManyRequests {
+		s.rateLimited.Add(1)
+	}
+}
@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// ContentStore is implemented by stores that keep a hash of each document's
+// content. A Syncer tracking content needs it.
+type ContentStore interface {
+	// ContentHashes returns the stored hashes of the documents with the
+	// given IDs, documents without one are missing from the map.
+	ContentHashes(ctx context.Context, ids []string) (map[string]string, error)
+	SetContentHashes(ctx context.Context, hashes map[string]string) error
+}
+
+// ContentChanged is published, after DocumentUpdated, for documents whose
+// html content differs from the last sync that saw it. Previous holds the
+// html content of the last sync only if the store keeps it.
+type ContentChanged struct {
+	Document     readwisereader.Document
+	Previous     readwisereader.Document
+	PreviousHash string
+	Hash         string
+}
+
+func (ContentChanged) event() {}
+
+// WithContentTracking makes Sync fetch the html content of documents and
+// publish ContentChanged when it changes, for example after a site was edited
+// or put behind a paywall. Fetching content makes syncs considerably slower.
+func WithContentTracking() Option {
+	return func(s *Syncer) {
+		s.trackContent = true
+	}
+}
+
+// WithHTMLContent makes Sync fetch and store the html content of documents,
+// without tracking its changes.
+func WithHTMLContent() Option {
+	return func(s *Syncer) {
+		s.withHTML = true
+	}
+}
+
+// contentHash hashes html content, ignoring differences in whitespace.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(content), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// contentEvents records the content hashes of docs and returns the events for
+// those that changed.
+func (s *Syncer) contentEvents(ctx context.Context, docs []readwisereader.Document, prev map[string]*readwisereader.Document) ([]Event, error) {
+	cs, ok := s.store.(ContentStore)
+	if !ok || !s.trackContent {
+		return nil, nil
+	}
+
+	var ids []string
+	for _, doc := range docs {
+		if doc.HTMLContent != "" {
+			ids = append(ids, doc.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	known, err := cs.ContentHashes(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	hashes := make(map[string]string, len(ids))
+	for _, doc := range docs {
+		if doc.HTMLContent == "" {
+			continue
+		}
+
+		hash := contentHash(doc.HTMLContent)
+		hashes[doc.ID] = hash
+
+		// The first hash of a document is a baseline, not a change.
+		if old, ok := known[doc.ID]; ok && old != hash {
+			event := ContentChanged{Document: doc, PreviousHash: old, Hash: hash}
+			if p := prev[doc.ID]; p != nil {
+				event.Previous = *p
+			}
+			events = append(events, event)
+		}
+	}
+
+	if err := cs.SetContentHashes(ctx, hashes); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"context"
+	"slices"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// Event is published to subscribers while syncing. It is one of
+// DocumentAdded, DocumentUpdated, DocumentArchived, ContentChanged, TagAdded,
+// DocumentDeleted or SyncCompleted.
+type Event interface {
+	event()
+}
+
+// DocumentAdded is published for documents not in the store before. The first
+// sync into an empty store publishes one for every document.
+type DocumentAdded struct {
+	Document readwisereader.Document
+}
+
+// DocumentUpdated is published for stored documents that changed.
+type DocumentUpdated struct {
+	Document readwisereader.Document
+	Previous readwisereader.Document
+}
+
+// DocumentArchived is published, after DocumentUpdated, for documents moved
+// to the archive.
+type DocumentArchived struct {
+	Document readwisereader.Document
+	// Location the document was moved from
+	From readwisereader.Location
+}
+
+// TagAdded is published for each tag a document gained, including the tags of
+// added documents.
+type TagAdded struct {
+	Document readwisereader.Document
+	Tag      string
+}
+
+// SyncCompleted is published once the run finished and its state was saved.
+type SyncCompleted struct {
+	Result Result
+}
+
+func (DocumentAdded) event()    {}
+func (DocumentUpdated) event()  {}
+func (DocumentArchived) event() {}
+func (TagAdded) event()         {}
+func (SyncCompleted) event()    {}
+
+// Subscriber receives events. Subscribers are called synchronously, in the
+// order they subscribed, after the documents involved were written to the
+// store.
+type Subscriber func(ctx context.Context, event Event)
+
+// Subscribe registers fn to receive the events of every following run.
+func (s *Syncer) Subscribe(fn Subscriber) {
+	s.subscribers = append(s.subscribers, fn)
+}
+
+func (s *Syncer) publish(ctx context.Context, event Event) {
+	for _, fn := range s.subscribers {
+		fn(ctx, event)
+	}
+}
+
+// documentEvents derives the events for doc given the stored version of it,
+// nil if there was none.
+func documentEvents(doc readwisereader.Document, prev *readwisereader.Document) []Event {
+	var events []Event
+	if prev == nil {
+		events = append(events, DocumentAdded{Document: doc})
+	} else if !prev.UpdatedAt.Equal(doc.UpdatedAt) {
+		events = append(events, DocumentUpdated{Document: doc, Previous: *prev})
+		if doc.Location == readwisereader.LocationArchive && prev.Location != readwisereader.LocationArchive {
+			events = append(events, DocumentArchived{Document: doc, From: prev.Location})
+		}
+	}
+
+	var tags []string
+	for tag := range doc.Tags {
+		if prev != nil {
+			if _, ok := prev.Tags[tag]; ok {
+				continue
+			}
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	for _, tag := range tags {
+		events = append(events, TagAdded{Document: doc, Tag: tag})
+	}
+
+	return events
+}
@@ -0,0 +1,433 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	gosync "sync"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// FileStore is a Store keeping everything in a single JSON file. The decoded
+// file is kept in memory and only read again when it changes on disk. Writes
+// take an advisory lock on a .lock file next to it, so processes sharing the
+// store don't overwrite each other's changes.
+type FileStore struct {
+	path string
+	mu   gosync.Mutex
+
+	data    *fileStoreData
+	modTime time.Time
+	size    int64
+
+	// batches counts the Batch calls in progress, which hold unlock. Changes
+	// made during a batch are only written when the last one ends.
+	batches int
+	unlock  func()
+	dirty   bool
+}
+
+var (
+	_ Store        = (*FileStore)(nil)
+	_ HistoryStore = (*FileStore)(nil)
+	_ QueueStore   = (*FileStore)(nil)
+	_ ContentStore = (*FileStore)(nil)
+	_ Batcher      = (*FileStore)(nil)
+)
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+type fileStoreData struct {
+	State     State                              `json:"state"`
+	Documents map[string]readwisereader.Document `json:"documents"`
+	History   []Transition                       `json:"history"`
+	Queue     []Operation                        `json:"queue,omitempty"`
+	// Content hashes by document ID
+	ContentHashes map[string]string `json:"content_hashes,omitempty"`
+}
+
+func (s *FileStore) State(ctx context.Context) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return State{}, err
+	}
+
+	return data.State, nil
+}
+
+func (s *FileStore) SaveState(ctx context.Context, state State) error {
+	return s.update(func(data *fileStoreData) error {
+		data.State = state
+		return nil
+	})
+}
+
+func (s *FileStore) Put(ctx context.Context, docs []readwisereader.Document) error {
+	return s.update(func(data *fileStoreData) error {
+		for _, doc := range docs {
+			data.Documents[doc.ID] = doc
+		}
+
+		return nil
+	})
+}
+
+func (s *FileStore) Delete(ctx context.Context, ids []string) error {
+	return s.update(func(data *fileStoreData) error {
+		for _, id := range ids {
+			delete(data.Documents, id)
+			delete(data.ContentHashes, id)
+		}
+
+		return nil
+	})
+}
+
+func (s *FileStore) Document(ctx context.Context, id string) (*readwisereader.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := data.Documents[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &doc, nil
+}
+
+func (s *FileStore) Documents(ctx context.Context) ([]readwisereader.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]readwisereader.Document, 0, len(data.Documents))
+	for _, doc := range data.Documents {
+		docs = append(docs, doc)
+	}
+
+	slices.SortFunc(docs, func(a, b readwisereader.Document) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+
+	return docs, nil
+}
+
+func (s *FileStore) AddTransitions(ctx context.Context, transitions []Transition) error {
+	return s.update(func(data *fileStoreData) error {
+		for _, t := range transitions {
+			i := slices.IndexFunc(data.History, func(h Transition) bool {
+				return h.DocumentID == t.DocumentID && h.Day == t.Day
+			})
+			if i < 0 {
+				data.History = append(data.History, t)
+				continue
+			}
+
+			h := &data.History[i]
+			h.At = t.At
+			h.ToLocation = t.ToLocation
+			h.ToProgress = t.ToProgress
+			h.WordCount = t.WordCount
+		}
+
+		return nil
+	})
+}
+
+func (s *FileStore) Transitions(ctx context.Context, since time.Time) ([]Transition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	day := since.Local().Format(dayFormat)
+
+	var transitions []Transition
+	for _, t := range data.History {
+		if t.Day >= day {
+			transitions = append(transitions, t)
+		}
+	}
+
+	slices.SortStableFunc(transitions, func(a, b Transition) int {
+		return a.At.Compare(b.At)
+	})
+
+	return transitions, nil
+}
+
+func (s *FileStore) Enqueue(ctx context.Context, op Operation) error {
+	return s.update(func(data *fileStoreData) error {
+		data.Queue = append(data.Queue, op)
+		return nil
+	})
+}
+
+func (s *FileStore) Operations(ctx context.Context) ([]Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(data.Queue), nil
+}
+
+func (s *FileStore) UpdateOperation(ctx context.Context, op Operation) error {
+	return s.update(func(data *fileStoreData) error {
+		i := slices.IndexFunc(data.Queue, func(o Operation) bool {
+			return o.ID == op.ID
+		})
+		if i < 0 {
+			return fmt.Errorf("operation %s is not queued", op.ID)
+		}
+
+		data.Queue[i] = op
+		return nil
+	})
+}
+
+func (s *FileStore) RemoveOperations(ctx context.Context, ids []string) error {
+	return s.update(func(data *fileStoreData) error {
+		data.Queue = slices.DeleteFunc(data.Queue, func(o Operation) bool {
+			return slices.Contains(ids, o.ID)
+		})
+		return nil
+	})
+}
+
+func (s *FileStore) ContentHashes(ctx context.Context, ids []string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(ids))
+	for _, id := range ids {
+		if hash, ok := data.ContentHashes[id]; ok {
+			hashes[id] = hash
+		}
+	}
+
+	return hashes, nil
+}
+
+func (s *FileStore) SetContentHashes(ctx context.Context, hashes map[string]string) error {
+	return s.update(func(data *fileStoreData) error {
+		if data.ContentHashes == nil {
+			data.ContentHashes = make(map[string]string, len(hashes))
+		}
+		for id, hash := range hashes {
+			data.ContentHashes[id] = hash
+		}
+
+		return nil
+	})
+}
+
+// Batch runs fn with the store locked, writing the changes fn makes once
+// when it returns rather than on every call.
+func (s *FileStore) Batch(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.mu.Lock()
+	if s.batches == 0 {
+		unlock, err := s.lock()
+		if err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		s.unlock = unlock
+	}
+	s.batches++
+	s.mu.Unlock()
+
+	err := fn(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches--
+	if s.batches > 0 {
+		return err
+	}
+
+	if s.dirty {
+		s.dirty = false
+		if werr := s.write(s.data); werr != nil {
+			err = errors.Join(err, werr)
+		}
+	}
+
+	s.unlock()
+	s.unlock = nil
+	return err
+}
+
+// update applies fn to the stored data and writes it, unless a batch is in
+// progress which writes it at its end.
+func (s *FileStore) update(fn func(data *fileStoreData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.batches == 0 {
+		unlock, err := s.lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(data); err != nil {
+		return err
+	}
+
+	if s.batches > 0 {
+		s.data, s.dirty = data, true
+		return nil
+	}
+
+	return s.write(data)
+}
+
+// lock takes the advisory lock other processes writing the store wait on. The
+// store is read again after taking it, as it may have changed meanwhile.
+func (s *FileStore) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(s.path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("lock store: %w", err)
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock store: %w", err)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+func (s *FileStore) load() (*fileStoreData, error) {
+	// Changes of the batch in progress are newer than the file.
+	if s.dirty {
+		return s.data, nil
+	}
+
+	data := fileStoreData{
+		Documents: map[string]readwisereader.Document{},
+	}
+
+	fi, err := os.Stat(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read store: %w", err)
+	}
+
+	if s.data != nil && fi.ModTime().Equal(s.modTime) && fi.Size() == s.size {
+		return s.data, nil
+	}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("read store: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("decode store: %w", err)
+	}
+
+	if data.Documents == nil {
+		data.Documents = map[string]readwisereader.Document{}
+	}
+
+	s.data = &data
+	s.modTime = fi.ModTime()
+	s.size = fi.Size()
+	return &data, nil
+}
+
+func (s *FileStore) write(data *fileStoreData) error {
+	// The data may already be changed in memory, read the file again next
+	// time unless writing it succeeds.
+	s.data = nil
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so an interrupted write never leaves
+	// a truncated store behind.
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write store: %w", err)
+	}
+
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("write store: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.data = data
+	s.modTime = fi.ModTime()
+	s.size = fi.Size()
+	return nil
+}
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// Transition is how a document's location and reading progress changed over
+// one day. Several changes on the same day collapse into one transition
+// spanning all of them.
+type Transition struct {
+	DocumentID   string                  `json:"document_id"`
+	Day          string                  `json:"day"`
+	At           time.Time               `json:"at"`
+	FromLocation readwisereader.Location `json:"from_location"`
+	ToLocation   readwisereader.Location `json:"to_location"`
+	FromProgress float64                 `json:"from_progress"`
+	ToProgress   float64                 `json:"to_progress"`
+	WordCount    int                     `json:"word_count"`
+}
+
+// WordsRead estimates the number of words read during the transition.
+func (t Transition) WordsRead() int {
+	delta := t.ToProgress - t.FromProgress
+	if delta <= 0 {
+		return 0
+	}
+
+	return int(delta * float64(t.WordCount))
+}
+
+// HistoryStore is implemented by stores that keep a history of transitions.
+// A Syncer records transitions whenever its store implements it.
+type HistoryStore interface {
+	// AddTransitions records transitions, merging each into an existing
+	// transition for the same document and day.
+	AddTransitions(ctx context.Context, transitions []Transition) error
+	// Transitions returns the transitions on or after the day of since,
+	// oldest first.
+	Transitions(ctx context.Context, since time.Time) ([]Transition, error)
+}
+
+const dayFormat = time.DateOnly
+
+func (s *Syncer) recordHistory(ctx context.Context, docs []readwisereader.Document, previous map[string]*readwisereader.Document) error {
+	history, ok := s.store.(HistoryStore)
+	if !ok {
+		return nil
+	}
+
+	var transitions []Transition
+	for _, doc := range docs {
+		prev := previous[doc.ID]
+
+		// Nothing to compare against for documents seen for the first time,
+		// recording them would credit a whole library to the day of the
+		// first sync.
+		if prev == nil {
+			continue
+		}
+
+		if prev.Location == doc.Location && prev.ReadingProgress == doc.ReadingProgress {
+			continue
+		}
+
+		transitions = append(transitions, Transition{
+			DocumentID:   doc.ID,
+			Day:          doc.UpdatedAt.Local().Format(dayFormat),
+			At:           doc.UpdatedAt,
+			FromLocation: prev.Location,
+			ToLocation:   doc.Location,
+			FromProgress: prev.ReadingProgress,
+			ToProgress:   doc.ReadingProgress,
+			WordCount:    doc.WordCount,
+		})
+	}
+
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	return history.AddTransitions(ctx, transitions)
+}
@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package sync
+
+import "os"
+
+// Platforms without file locking rely on the in-process mutex alone.
+
+func lockFile(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
@@ -0,0 +1,242 @@
+package sync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+type OperationKind string
+
+const (
+	OperationSave   OperationKind = "save"
+	OperationUpdate OperationKind = "update"
+)
+
+// Operation is a Save or Update waiting to be sent to the API.
+type Operation struct {
+	ID       string        `json:"id"`
+	Kind     OperationKind `json:"kind"`
+	QueuedAt time.Time     `json:"queued_at"`
+
+	Save *readwisereader.SaveParams `json:"save,omitempty"`
+
+	DocumentID string                       `json:"document_id,omitempty"`
+	Update     *readwisereader.UpdateParams `json:"update,omitempty"`
+	// UpdateParams doesn't encode its field mask and precondition, they are
+	// kept here and restored when the operation is sent.
+	UpdateFields    []readwisereader.UpdateField `json:"update_fields,omitempty"`
+	UnmodifiedSince time.Time                    `json:"unmodified_since"`
+
+	// Number of times sending the operation failed
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+	// Set once the API rejected the operation, failed operations are not
+	// sent again
+	Failed bool `json:"failed,omitempty"`
+}
+
+// QueueStore persists queued operations.
+type QueueStore interface {
+	Enqueue(ctx context.Context, op Operation) error
+	// Operations returns the queued operations, oldest first.
+	Operations(ctx context.Context) ([]Operation, error)
+	// UpdateOperation replaces the queued operation with the same ID.
+	UpdateOperation(ctx context.Context, op Operation) error
+	RemoveOperations(ctx context.Context, ids []string) error
+}
+
+// Queue writes saves and updates to a store first and sends them to the API
+// when flushed, so that they survive being offline or rate limited.
+type Queue struct {
+	client readwisereader.API
+	store  QueueStore
+}
+
+func NewQueue(client readwisereader.API, store QueueStore) *Queue {
+	return &Queue{
+		client: client,
+		store:  store,
+	}
+}
+
+// FlushResult is the outcome of a Flush.
+type FlushResult struct {
+	// Number of operations sent
+	Sent int
+	// Number of operations the API rejected during this flush
+	Failed int
+	// Number of operations still queued, failed ones included
+	Remaining int
+}
+
+// Save queues a save.
+func (q *Queue) Save(ctx context.Context, params readwisereader.SaveParams) (*Operation, error) {
+	return q.enqueue(ctx, Operation{
+		Kind: OperationSave,
+		Save: &params,
+	})
+}
+
+// Update queues an update of the document with the given ID.
+func (q *Queue) Update(ctx context.Context, id string, params readwisereader.UpdateParams) (*Operation, error) {
+	return q.enqueue(ctx, Operation{
+		Kind:            OperationUpdate,
+		DocumentID:      id,
+		Update:          &params,
+		UpdateFields:    params.Fields,
+		UnmodifiedSince: params.UnmodifiedSince,
+	})
+}
+
+func (q *Queue) enqueue(ctx context.Context, op Operation) (*Operation, error) {
+	id, err := newOperationID()
+	if err != nil {
+		return nil, err
+	}
+
+	op.ID = id
+	op.QueuedAt = time.Now()
+	if err := q.store.Enqueue(ctx, op); err != nil {
+		return nil, err
+	}
+
+	return &op, nil
+}
+
+// Operations returns the queued operations, oldest first.
+func (q *Queue) Operations(ctx context.Context) ([]Operation, error) {
+	return q.store.Operations(ctx)
+}
+
+// Remove drops queued operations without sending them.
+func (q *Queue) Remove(ctx context.Context, ids ...string) error {
+	return q.store.RemoveOperations(ctx, ids)
+}
+
+// Retry clears the failed mark of the given operations, or of every failed
+// one when no IDs are given, so that the next Flush sends them again. It
+// returns the number of operations it cleared.
+func (q *Queue) Retry(ctx context.Context, ids ...string) (int, error) {
+	ops, err := q.store.Operations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, op := range ops {
+		if !op.Failed || (len(ids) > 0 && !slices.Contains(ids, op.ID)) {
+			continue
+		}
+
+		op.Failed = false
+		if err := q.store.UpdateOperation(ctx, op); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// Flush sends queued operations in order. Operations the API rejects are
+// marked failed and skipped from then on. Flush stops at the first operation
+// that could not be sent for other reasons, rate limits or being offline,
+// returning the error along with how far it got; the operation stays queued.
+func (q *Queue) Flush(ctx context.Context) (FlushResult, error) {
+	var result FlushResult
+
+	ops, err := q.store.Operations(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for i, op := range ops {
+		if op.Failed {
+			result.Remaining++
+			continue
+		}
+
+		err := q.send(ctx, op)
+		if err == nil {
+			if err := q.store.RemoveOperations(ctx, []string{op.ID}); err != nil {
+				return result, err
+			}
+			result.Sent++
+			continue
+		}
+
+		op.Attempts++
+		op.LastError = err.Error()
+		op.Failed = rejected(err)
+		if uerr := q.store.UpdateOperation(ctx, op); uerr != nil {
+			return result, uerr
+		}
+
+		if !op.Failed {
+			result.Remaining += len(ops) - i
+			return result, fmt.Errorf("%s %s: %w", op.Kind, op.ID, err)
+		}
+
+		result.Failed++
+		result.Remaining++
+	}
+
+	return result, nil
+}
+
+func (q *Queue) send(ctx context.Context, op Operation) error {
+	switch op.Kind {
+	case OperationSave:
+		if op.Save == nil {
+			return fmt.Errorf("save without parameters")
+		}
+		_, err := q.client.Save(ctx, *op.Save)
+		return err
+	case OperationUpdate:
+		if op.Update == nil {
+			return fmt.Errorf("update without parameters")
+		}
+		params := *op.Update
+		params.Fields = op.UpdateFields
+		params.UnmodifiedSince = op.UnmodifiedSince
+		_, err := q.client.Update(ctx, op.DocumentID, params)
+		return err
+	default:
+		return fmt.Errorf("unknown operation kind: %q", op.Kind)
+	}
+}
+
+// rejected reports whether err means the API refused the operation, as
+// opposed to it not getting through at all. Only requests the API will keep
+// refusing count, anything else may go away by itself: auth errors once the
+// token is fixed, timeouts, rate limits and server errors.
+func rejected(err error) bool {
+	var apiErr *readwisereader.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity:
+			return true
+		default:
+			return false
+		}
+	}
+
+	return errors.Is(err, readwisereader.ErrNotFound) || errors.Is(err, readwisereader.ErrConflict)
+}
+
+func newOperationID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
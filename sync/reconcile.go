@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// ErrCannotDelete is returned when reconciling into a store that does not
+// implement DeleteStore.
+var ErrCannotDelete = errors.New("store does not support deleting documents")
+
+// DeleteStore is implemented by stores that can drop documents, which
+// reconciliation needs.
+type DeleteStore interface {
+	Delete(ctx context.Context, ids []string) error
+}
+
+// DocumentDeleted is published for stored documents that no longer exist
+// remotely.
+type DocumentDeleted struct {
+	Document readwisereader.Document
+}
+
+func (DocumentDeleted) event() {}
+
+// Option configures a Syncer.
+type Option func(*Syncer)
+
+// WithReconcileInterval makes Sync reconcile deletions when the last
+// reconciliation is older than d. Reconciling lists every remote document, so
+// d should be generous.
+func WithReconcileInterval(d time.Duration) Option {
+	return func(s *Syncer) {
+		s.reconcileEvery = d
+	}
+}
+
+// Reconcile compares the IDs of every remote document against the store and
+// deletes stored documents that are gone, publishing DocumentDeleted for each.
+// The list API does not report deletions, so this is the only way to notice
+// them. It returns the number of documents deleted.
+func (s *Syncer) Reconcile(ctx context.Context) (int, error) {
+	ctx = readwisereader.WithPriority(ctx, readwisereader.PriorityBackground)
+
+	deleter, ok := s.store.(DeleteStore)
+	if !ok {
+		return 0, ErrCannotDelete
+	}
+
+	startedAt := time.Now()
+
+	remote := map[string]struct{}{}
+	for page, err := range s.client.ListPaginate(ctx, readwisereader.ListParams{}) {
+		// Bail out before touching the store, a partial listing would look
+		// like mass deletion.
+		if err != nil {
+			return 0, err
+		}
+
+		for _, doc := range page.Results {
+			remote[doc.ID] = struct{}{}
+		}
+	}
+
+	docs, err := s.store.Documents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted []readwisereader.Document
+	for _, doc := range docs {
+		if _, ok := remote[doc.ID]; ok {
+			continue
+		}
+
+		// Documents created after the listing started may be missing from it.
+		if doc.CreatedAt.After(startedAt) {
+			continue
+		}
+
+		deleted = append(deleted, doc)
+	}
+
+	if len(deleted) > 0 {
+		ids := make([]string, len(deleted))
+		for i, doc := range deleted {
+			ids[i] = doc.ID
+		}
+
+		if err := deleter.Delete(ctx, ids); err != nil {
+			return 0, err
+		}
+	}
+
+	state, err := s.state.State(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	state.LastReconcileAt = startedAt
+	if err := s.state.SaveState(ctx, state); err != nil {
+		return 0, err
+	}
+
+	for _, doc := range deleted {
+		s.publish(ctx, DocumentDeleted{Document: doc})
+	}
+
+	return len(deleted), nil
+}
+
+func (s *Syncer) reconcileDue(state State) bool {
+	return s.reconcileEvery > 0 && time.Since(state.LastReconcileAt) >= s.reconcileEvery
+}
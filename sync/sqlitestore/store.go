@@ -0,0 +1,379 @@
+// Package sqlitestore is a sync.Store keeping documents in a SQLite database,
+// indexed for full-text search.
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	readwisereader "code.selman.me/go-readwisereader"
+	"code.selman.me/go-readwisereader/sync"
+	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/net/html"
+)
+
+// schema sets up the database. Documents are stored as JSON, with the
+// columns filtered on next to them. Metadata and content are indexed
+// separately so that searches can leave content out, the rows of the indexes
+// share the rowid of their document.
+const schema = `
+CREATE TABLE IF NOT EXISTS state (
+	id   INTEGER PRIMARY KEY CHECK (id = 1),
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+	rowid      INTEGER PRIMARY KEY,
+	id         TEXT NOT NULL UNIQUE,
+	parent_id  TEXT NOT NULL,
+	location   TEXT NOT NULL,
+	category   TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS document_tags (
+	document_id TEXT NOT NULL,
+	tag         TEXT NOT NULL,
+	PRIMARY KEY (document_id, tag)
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS metadata_fts USING fts4(title, author, summary, notes, tags);
+CREATE VIRTUAL TABLE IF NOT EXISTS content_fts USING fts4(content);
+`
+
+// Store is a sync.Store backed by a SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+var (
+	_ sync.Store       = (*Store)(nil)
+	_ sync.DeleteStore = (*Store)(nil)
+)
+
+// Open opens the database at path, creating it if it doesn't exist.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) State(ctx context.Context) (sync.State, error) {
+	var state sync.State
+
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM state WHERE id = 1`).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return state, fmt.Errorf("decode state: %w", err)
+	}
+
+	return state, nil
+}
+
+func (s *Store) SaveState(ctx context.Context, state sync.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO state (id, data) VALUES (1, ?)`, string(data))
+	return err
+}
+
+func (s *Store) Put(ctx context.Context, docs []readwisereader.Document) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		for _, doc := range docs {
+			if err := deleteDocument(ctx, tx, doc.ID); err != nil {
+				return err
+			}
+
+			if err := putDocument(ctx, tx, doc); err != nil {
+				return fmt.Errorf("put %s: %w", doc.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) Delete(ctx context.Context, ids []string) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		for _, id := range ids {
+			if err := deleteDocument(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) Document(ctx context.Context, id string) (*readwisereader.Document, error) {
+	docs, err := s.query(ctx, `SELECT data FROM documents WHERE id = ?`, id)
+	if err != nil || len(docs) == 0 {
+		return nil, err
+	}
+
+	return &docs[0], nil
+}
+
+// Documents returns every stored document, most recently updated first.
+func (s *Store) Documents(ctx context.Context) ([]readwisereader.Document, error) {
+	return s.query(ctx, `SELECT data FROM documents ORDER BY updated_at DESC`)
+}
+
+func (s *Store) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) query(ctx context.Context, query string, args ...any) ([]readwisereader.Document, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []readwisereader.Document
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var doc readwisereader.Document
+		if err := json.Unmarshal([]byte(data), &doc); err != nil {
+			return nil, fmt.Errorf("decode document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, rows.Err()
+}
+
+func putDocument(ctx context.Context, tx *sql.Tx, doc readwisereader.Document) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO documents (id, parent_id, location, category, updated_at, data) VALUES (?, ?, ?, ?, ?, ?)`,
+		doc.ID, doc.ParentID, string(doc.Location), string(doc.Category), doc.UpdatedAt.UTC().Format(time.RFC3339Nano), string(data),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowid, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	var tags []string
+	for key, tag := range doc.Tags {
+		name := tag.Name
+		if name == "" {
+			name = key
+		}
+		tags = append(tags, name)
+
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO document_tags (document_id, tag) VALUES (?, ?)`, doc.ID, strings.ToLower(name)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO metadata_fts (docid, title, author, summary, notes, tags) VALUES (?, ?, ?, ?, ?, ?)`,
+		rowid, doc.Title, doc.Author, doc.Summary, doc.Notes, strings.Join(tags, " "),
+	); err != nil {
+		return err
+	}
+
+	if doc.HTMLContent != "" {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO content_fts (docid, content) VALUES (?, ?)`, rowid, htmlText(doc.HTMLContent)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteDocument(ctx context.Context, tx *sql.Tx, id string) error {
+	var rowid int64
+	err := tx.QueryRowContext(ctx, `SELECT rowid FROM documents WHERE id = ?`, id).Scan(&rowid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, q := range []struct {
+		query string
+		arg   any
+	}{
+		{`DELETE FROM documents WHERE rowid = ?`, rowid},
+		{`DELETE FROM metadata_fts WHERE docid = ?`, rowid},
+		{`DELETE FROM content_fts WHERE docid = ?`, rowid},
+		{`DELETE FROM document_tags WHERE document_id = ?`, id},
+	} {
+		if _, err := tx.ExecContext(ctx, q.query, q.arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// htmlText returns the text of an HTML document for indexing.
+func htmlText(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+
+	var b strings.Builder
+	for n := range doc.Descendants() {
+		if n.Type == html.TextNode && (n.Parent == nil || n.Parent.Data != "script" && n.Parent.Data != "style") {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Query is a full-text search of the stored documents.
+type Query struct {
+	// Text is the words to look for, documents match when they contain all
+	// of them. A word ending in * matches as a prefix.
+	Text string
+	// Content includes the html content of documents in the search.
+	Content bool
+
+	// Documents match any of the locations and categories, or all of them
+	// when empty, and every one of the tags.
+	Locations  []readwisereader.Location
+	Categories []readwisereader.Category
+	Tags       []string
+
+	// Limit bounds the number of results, 0 for no limit.
+	Limit int
+}
+
+// Search returns the documents matching q, most recently updated first.
+func (s *Store) Search(ctx context.Context, q Query) ([]readwisereader.Document, error) {
+	var where []string
+	var args []any
+
+	if match := matchExpr(q.Text); match != "" {
+		cond := `rowid IN (SELECT docid FROM metadata_fts WHERE metadata_fts MATCH ?)`
+		args = append(args, match)
+		if q.Content {
+			cond = "(" + cond + ` OR rowid IN (SELECT docid FROM content_fts WHERE content_fts MATCH ?))`
+			args = append(args, match)
+		}
+		where = append(where, cond)
+	}
+
+	if len(q.Locations) > 0 {
+		where = append(where, "location IN ("+placeholders(len(q.Locations))+")")
+		for _, location := range q.Locations {
+			args = append(args, string(location))
+		}
+	}
+
+	if len(q.Categories) > 0 {
+		where = append(where, "category IN ("+placeholders(len(q.Categories))+")")
+		for _, category := range q.Categories {
+			args = append(args, string(category))
+		}
+	}
+
+	for _, tag := range q.Tags {
+		where = append(where, `id IN (SELECT document_id FROM document_tags WHERE tag = ?)`)
+		args = append(args, strings.ToLower(strings.TrimSpace(tag)))
+	}
+
+	query := `SELECT data FROM documents`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY updated_at DESC"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	return s.query(ctx, query, args...)
+}
+
+// Match returns up to limit top-level documents whose ID starts with s or
+// whose title contains it, ignoring case, most recently updated first.
+func (s *Store) Match(ctx context.Context, text string, limit int) ([]readwisereader.Document, error) {
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+	return s.query(ctx,
+		`SELECT data FROM documents WHERE parent_id = '' AND (id LIKE ? ESCAPE '\' OR json_extract(data, '$.Title') LIKE ? ESCAPE '\') ORDER BY updated_at DESC LIMIT ?`,
+		pattern+"%", "%"+pattern+"%", limit,
+	)
+}
+
+// matchExpr turns the words of text into a full-text query matching all of
+// them, quoted so that punctuation isn't taken for query syntax.
+func matchExpr(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.NewReplacer(`"`, "", "*", "").Replace(word)
+		// Punctuation on its own has no tokens and would match nothing.
+		if !strings.ContainsFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+
+		if prefix {
+			word += "*"
+		}
+		terms = append(terms, `"`+word+`"`)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,235 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	readwisereader "code.selman.me/go-readwisereader"
+)
+
+// State is the bookkeeping a Syncer persists between runs.
+type State struct {
+	LastSyncAt      time.Time
+	LastReconcileAt time.Time
+
+	// Cursor is the page cursor of a sync that was interrupted, which the
+	// next sync resumes from, and CursorStartedAt the time that sync started.
+	Cursor          string
+	CursorStartedAt time.Time
+}
+
+// StateStore persists sync state.
+type StateStore interface {
+	State(ctx context.Context) (State, error)
+	SaveState(ctx context.Context, state State) error
+}
+
+// Store persists synced documents and, unless the Syncer is given a separate
+// StateStore, sync state.
+type Store interface {
+	StateStore
+	Put(ctx context.Context, docs []readwisereader.Document) error
+	// Document returns the stored document with the given ID, or nil.
+	Document(ctx context.Context, id string) (*readwisereader.Document, error)
+	Documents(ctx context.Context) ([]readwisereader.Document, error)
+}
+
+// Batcher is implemented by stores that can apply several writes at once,
+// which Sync uses to write each page in one go.
+type Batcher interface {
+	// Batch runs fn, applying the writes made to the store during it
+	// together.
+	Batch(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Syncer struct {
+	client      readwisereader.API
+	store       Store
+	state       StateStore
+	subscribers []Subscriber
+
+	reconcileEvery time.Duration
+	trackContent   bool
+	withHTML       bool
+	updatedAfter   time.Time
+}
+
+func New(client readwisereader.API, store Store, opts ...Option) *Syncer {
+	s := &Syncer{
+		client: client,
+		store:  store,
+		state:  store,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+type Result struct {
+	// Number of documents fetched during the run
+	Documents int
+	// Number of documents deleted by reconciliation, if it ran
+	Deleted int
+	// Timestamp recorded as the new high-water mark
+	SyncedAt time.Time
+}
+
+// WithStateStore keeps sync state in state rather than the document store.
+func WithStateStore(state StateStore) Option {
+	return func(s *Syncer) {
+		s.state = state
+	}
+}
+
+// WithUpdatedAfter makes Sync fetch the documents updated after t when that
+// is before the last run, to fetch recent changes again. A t after the last
+// run is ignored, so that no document updated in between is skipped, and so
+// is t on the first run, which fetches the whole library. An interrupted run
+// is started over instead of resumed when t applies.
+func WithUpdatedAfter(t time.Time) Option {
+	return func(s *Syncer) {
+		s.updatedAfter = t
+	}
+}
+
+// Sync fetches every document updated since the last successful run and
+// writes it to the store. A run that is interrupted records the page it got
+// to, and the next run picks up from there.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	// Syncing is bulk work, let interactive requests sharing the client go first.
+	ctx = readwisereader.WithPriority(ctx, readwisereader.PriorityBackground)
+
+	state, err := s.state.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAfter := state.LastSyncAt
+	if !s.updatedAfter.IsZero() && s.updatedAfter.Before(state.LastSyncAt) {
+		// The cursor of an interrupted run lists since its own time.
+		updatedAfter = s.updatedAfter
+		state.Cursor, state.CursorStartedAt = "", time.Time{}
+	}
+
+	// Take the timestamp before fetching so that documents updated while the
+	// run is in progress are picked up again next time.
+	startedAt := time.Now()
+	if state.Cursor != "" {
+		startedAt = state.CursorStartedAt
+	}
+
+	params := readwisereader.ListParams{
+		UpdatedAfter:    updatedAfter,
+		WithHTMLContent: s.trackContent || s.withHTML,
+		PageCursor:      state.Cursor,
+	}
+
+	var result Result
+	for page, err := range s.client.ListPaginate(ctx, params) {
+		if errors.Is(err, readwisereader.ErrInvalidCursor) && state.Cursor != "" {
+			// The cursor of the interrupted run expired, start over.
+			state.Cursor, state.CursorStartedAt = "", time.Time{}
+			if err := s.state.SaveState(ctx, state); err != nil {
+				return nil, err
+			}
+			return s.Sync(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var prev map[string]*readwisereader.Document
+		var contentEvents []Event
+		err = s.batch(ctx, func(ctx context.Context) error {
+			// Every page before this one is stored, resume here if interrupted.
+			if page.Cursor != "" && page.Cursor != state.Cursor {
+				state.Cursor, state.CursorStartedAt = page.Cursor, startedAt
+				if err := s.state.SaveState(ctx, state); err != nil {
+					return err
+				}
+			}
+
+			var err error
+			prev, err = s.previous(ctx, page.Results)
+			if err != nil {
+				return err
+			}
+
+			if err := s.recordHistory(ctx, page.Results, prev); err != nil {
+				return err
+			}
+
+			contentEvents, err = s.contentEvents(ctx, page.Results, prev)
+			if err != nil {
+				return err
+			}
+
+			return s.store.Put(ctx, page.Results)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(s.subscribers) > 0 {
+			for _, doc := range page.Results {
+				for _, event := range documentEvents(doc, prev[doc.ID]) {
+					s.publish(ctx, event)
+				}
+			}
+			for _, event := range contentEvents {
+				s.publish(ctx, event)
+			}
+		}
+
+		result.Documents += len(page.Results)
+	}
+
+	state.LastSyncAt = startedAt
+	state.Cursor, state.CursorStartedAt = "", time.Time{}
+	if err := s.state.SaveState(ctx, state); err != nil {
+		return nil, err
+	}
+
+	if s.reconcileDue(state) {
+		result.Deleted, err = s.Reconcile(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile: %w", err)
+		}
+	}
+
+	result.SyncedAt = startedAt
+	s.publish(ctx, SyncCompleted{Result: result})
+	return &result, nil
+}
+
+// batch runs fn in a batch of the store when it supports them.
+func (s *Syncer) batch(ctx context.Context, fn func(ctx context.Context) error) error {
+	if b, ok := s.store.(Batcher); ok {
+		return b.Batch(ctx, fn)
+	}
+
+	return fn(ctx)
+}
+
+// previous looks up the stored version of docs, keyed by ID. Documents not
+// stored yet are missing from the map.
+func (s *Syncer) previous(ctx context.Context, docs []readwisereader.Document) (map[string]*readwisereader.Document, error) {
+	prev := make(map[string]*readwisereader.Document, len(docs))
+	for _, doc := range docs {
+		d, err := s.store.Document(ctx, doc.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if d != nil {
+			prev[doc.ID] = d
+		}
+	}
+
+	return prev, nil
+}
@@ -0,0 +1,196 @@
+package readwisereader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Tag is a tag on a document.
+type Tag struct {
+	Name string
+	// Type is how the tag was added, "manual" for tags added by hand.
+	Type    string
+	Created time.Time
+}
+
+// UnmarshalJSON accepts both the API shape of a tag, with created in
+// milliseconds, and the shape Tag marshals to.
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Name    string    `json:"name"`
+		Type    string    `json:"type"`
+		Created dateValue `json:"created"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*t = Tag{Name: v.Name, Type: v.Type, Created: v.Created.Time}
+	return nil
+}
+
+// LibraryTag is a tag in use somewhere in the Reader library.
+type LibraryTag struct {
+	// Normalized form of the name, the key of Document.Tags
+	Key  string
+	Name string
+}
+
+// HighlightTag is a tag on a highlight in the classic Readwise API.
+type HighlightTag struct {
+	ID   int
+	Name string
+}
+
+type libraryTagsResponse struct {
+	Results []struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"results"`
+	NextPageCursor string `json:"nextPageCursor"`
+}
+
+type highlightTag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (t highlightTag) toHighlightTag() HighlightTag {
+	return HighlightTag{ID: t.ID, Name: t.Name}
+}
+
+type highlightTagsResponse struct {
+	Results []highlightTag `json:"results"`
+	Next    *string        `json:"next"`
+}
+
+// ListTags returns every tag used in the Reader library.
+func (c *Client) ListTags(ctx context.Context) ([]LibraryTag, error) {
+	var tags []LibraryTag
+	var cursor string
+	for {
+		u := c.addr + "/tags/"
+		if cursor != "" {
+			u += "?" + url.Values{"pageCursor": {cursor}}.Encode()
+		}
+
+		var tr libraryTagsResponse
+		if err := c.getJSON(ctx, u, &tr); err != nil {
+			return nil, err
+		}
+
+		for _, t := range tr.Results {
+			tags = append(tags, LibraryTag{Key: t.Key, Name: t.Name})
+		}
+
+		if tr.NextPageCursor == "" {
+			return tags, nil
+		}
+		cursor = tr.NextPageCursor
+	}
+}
+
+// HighlightTags returns the tags of a highlight, by its classic Readwise ID.
+func (c *Client) HighlightTags(ctx context.Context, highlightID int) ([]HighlightTag, error) {
+	var tags []HighlightTag
+	u := fmt.Sprintf("%s/highlights/%d/tags/", c.addrV2, highlightID)
+	for {
+		var hr highlightTagsResponse
+		if err := c.getJSON(ctx, u, &hr); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("highlight %d: %w", highlightID, err)
+			}
+			return nil, err
+		}
+
+		for _, t := range hr.Results {
+			tags = append(tags, t.toHighlightTag())
+		}
+
+		if hr.Next == nil || *hr.Next == "" {
+			return tags, nil
+		}
+		u = *hr.Next
+	}
+}
+
+// TagHighlight adds a tag to a highlight, by its classic Readwise ID.
+func (c *Client) TagHighlight(ctx context.Context, highlightID int, name string) (*HighlightTag, error) {
+	u := fmt.Sprintf("%s/highlights/%d/tags/", c.addrV2, highlightID)
+
+	b, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var t highlightTag
+	if err := decodeBody(resp, &t); err != nil {
+		return nil, err
+	}
+
+	tag := t.toHighlightTag()
+	return &tag, nil
+}
+
+// DeleteHighlightTag removes a tag from a highlight.
+func (c *Client) DeleteHighlightTag(ctx context.Context, highlightID, tagID int) error {
+	u := fmt.Sprintf("%s/highlights/%d/tags/%d", c.addrV2, highlightID, tagID)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("tag %d of highlight %d: %w", tagID, highlightID, ErrNotFound)
+	default:
+		return newAPIError(resp)
+	}
+}
+
+func (c *Client) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return decodeBody(resp, v)
+}
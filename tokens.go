@@ -0,0 +1,85 @@
+package readwisereader
+
+import (
+	"sync"
+	"time"
+)
+
+// NewClientWithTokens returns a client that uses the first token and rotates
+// to the next when the API rejects the current one or rate limits it, for
+// shared accounts or exports that outgrow a single token's budget. It panics
+// if no token is given.
+func NewClientWithTokens(tokens ...string) *Client {
+	if len(tokens) == 0 {
+		panic("readwisereader: no tokens")
+	}
+
+	return NewClient(tokens[0], WithTokens(tokens...))
+}
+
+// tokenRing tracks which token is in use and which are rate limited.
+type tokenRing struct {
+	mu           sync.Mutex
+	tokens       []string
+	current      int
+	limitedUntil []time.Time
+}
+
+func newTokenRing(tokens []string) *tokenRing {
+	return &tokenRing{
+		tokens:       tokens,
+		limitedUntil: make([]time.Time, len(tokens)),
+	}
+}
+
+func (r *tokenRing) len() int {
+	return len(r.tokens)
+}
+
+// get returns the token to use and its index, for reporting back.
+func (r *tokenRing) get() (int, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current, r.tokens[r.current]
+}
+
+// rotate moves off token i, skipping tokens known to be rate limited. It
+// reports whether there is another token to try.
+func (r *tokenRing) rotate(i int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Someone else rotated already.
+	if r.current != i {
+		return true
+	}
+
+	now := time.Now()
+	for n := 1; n < len(r.tokens); n++ {
+		next := (i + n) % len(r.tokens)
+		if !now.Before(r.limitedUntil[next]) {
+			r.current = next
+			return true
+		}
+	}
+
+	return false
+}
+
+// limit records that token i is rate limited for d. It returns how long until
+// some token is usable again, zero if one is right away.
+func (r *tokenRing) limit(i int, d time.Duration) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.limitedUntil[i] = now.Add(d)
+
+	wait := d
+	for _, until := range r.limitedUntil {
+		wait = min(wait, max(until.Sub(now), 0))
+	}
+
+	return wait
+}
@@ -0,0 +1,179 @@
+// Package warc writes WARC/1.1 files, the ISO 28500 web archive format.
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const version = "WARC/1.1"
+
+// Record types.
+const (
+	TypeWarcinfo = "warcinfo"
+	TypeRequest  = "request"
+	TypeResponse = "response"
+	TypeResource = "resource"
+)
+
+type Record struct {
+	Type      string
+	TargetURI string
+	Date      time.Time
+	// Content-Type of the record block
+	ContentType string
+	// Additional WARC named fields
+	Headers map[string]string
+	Block   []byte
+}
+
+// Writer writes WARC records. When compressing, every record is its own gzip
+// member, as the spec recommends, so readers can seek to individual records.
+type Writer struct {
+	w        io.Writer
+	compress bool
+}
+
+func NewWriter(w io.Writer, compress bool) *Writer {
+	return &Writer{
+		w:        w,
+		compress: compress,
+	}
+}
+
+// Write writes r and returns its generated record ID.
+func (w *Writer) Write(r Record) (string, error) {
+	id, err := newRecordID()
+	if err != nil {
+		return "", err
+	}
+
+	date := r.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\r\n", version)
+	fmt.Fprintf(&b, "WARC-Type: %s\r\n", r.Type)
+	fmt.Fprintf(&b, "WARC-Record-ID: %s\r\n", id)
+	fmt.Fprintf(&b, "WARC-Date: %s\r\n", date.UTC().Format(time.RFC3339))
+	if r.TargetURI != "" {
+		fmt.Fprintf(&b, "WARC-Target-URI: %s\r\n", r.TargetURI)
+	}
+	fmt.Fprintf(&b, "WARC-Block-Digest: %s\r\n", digest(r.Block))
+
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, r.Headers[name])
+	}
+
+	if r.ContentType != "" {
+		fmt.Fprintf(&b, "Content-Type: %s\r\n", r.ContentType)
+	}
+	fmt.Fprintf(&b, "Content-Length: %s\r\n", strconv.Itoa(len(r.Block)))
+	b.WriteString("\r\n")
+	b.Write(r.Block)
+	b.WriteString("\r\n\r\n")
+
+	if !w.compress {
+		_, err := w.w.Write(b.Bytes())
+		return id, err
+	}
+
+	gz := gzip.NewWriter(w.w)
+	if _, err := gz.Write(b.Bytes()); err != nil {
+		return "", err
+	}
+
+	return id, gz.Close()
+}
+
+// WriteWarcinfo writes the warcinfo record describing the file.
+func (w *Writer) WriteWarcinfo(fields map[string]string) (string, error) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, fields[k])
+	}
+
+	return w.Write(Record{
+		Type:        TypeWarcinfo,
+		ContentType: "application/warc-fields",
+		Block:       b.Bytes(),
+	})
+}
+
+// WriteExchange writes a request record and the response record for it. The
+// response body must not have been read yet; it is consumed and closed.
+func (w *Writer) WriteExchange(req *http.Request, resp *http.Response) error {
+	reqBlock, err := httputil.DumpRequestOut(req, false)
+	if err != nil {
+		return err
+	}
+
+	respBlock, err := httputil.DumpResponse(resp, true)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	date := time.Now()
+	target := req.URL.String()
+
+	respID, err := w.Write(Record{
+		Type:        TypeResponse,
+		TargetURI:   target,
+		Date:        date,
+		ContentType: "application/http;msgtype=response",
+		Block:       respBlock,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(Record{
+		Type:        TypeRequest,
+		TargetURI:   target,
+		Date:        date,
+		ContentType: "application/http;msgtype=request",
+		Headers:     map[string]string{"WARC-Concurrent-To": respID},
+		Block:       reqBlock,
+	})
+	return err
+}
+
+func newRecordID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
+
+func digest(b []byte) string {
+	sum := sha1.Sum(b)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}